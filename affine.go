@@ -0,0 +1,57 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import "math"
+
+// Affine is a 2D affine transformation matrix.
+//
+//	x' = A*x + C*y + E
+//	y' = B*x + D*y + F
+type Affine struct {
+	A, B, C, D, E, F float64
+}
+
+// Identity is the affine transformation that leaves points unchanged.
+var Identity = Affine{A: 1, D: 1}
+
+// Translate returns an affine transformation that moves points by delta.
+func Translate(deltaX, deltaY float64) Affine {
+	return Affine{A: 1, D: 1, E: deltaX, F: deltaY}
+}
+
+// Rotation returns an affine transformation that rotates points
+// counter-clockwise around the origin (0,0) by radians.
+func Rotation(radians float64) Affine {
+	sin, cos := math.Sincos(radians)
+	return Affine{A: cos, B: sin, C: -sin, D: cos}
+}
+
+// Scaling returns an affine transformation that scales points relative to
+// the origin (0,0).
+func Scaling(scaleX, scaleY float64) Affine {
+	return Affine{A: scaleX, D: scaleY}
+}
+
+// Multiply composes two transformations. The returned transformation is
+// equivalent to applying m first and then n.
+func (m Affine) Multiply(n Affine) Affine {
+	return Affine{
+		A: n.A*m.A + n.C*m.B,
+		B: n.B*m.A + n.D*m.B,
+		C: n.A*m.C + n.C*m.D,
+		D: n.B*m.C + n.D*m.D,
+		E: n.A*m.E + n.C*m.F + n.E,
+		F: n.B*m.E + n.D*m.F + n.F,
+	}
+}
+
+// Apply the transformation to a point
+func (m Affine) Apply(point Point) Point {
+	return Point{
+		X: m.A*point.X + m.C*point.Y + m.E,
+		Y: m.B*point.X + m.D*point.Y + m.F,
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math"
+	"testing"
+)
+
+func pointsNear(a, b Point, tolerance float64) bool {
+	return math.Abs(a.X-b.X) <= tolerance && math.Abs(a.Y-b.Y) <= tolerance
+}
+
+func TestAffineApply(t *testing.T) {
+	expect(t, Identity.Apply(P(3, 4)) == P(3, 4))
+	expect(t, Translate(5, 6).Apply(P(3, 4)) == P(8, 10))
+	expect(t, Scaling(2, 3).Apply(P(3, 4)) == P(6, 12))
+	expect(t, pointsNear(Rotation(math.Pi/2).Apply(P(1, 0)), P(0, 1), 1e-12))
+	expect(t, pointsNear(Rotation(math.Pi).Apply(P(1, 0)), P(-1, 0), 1e-12))
+}
+
+func TestAffineMultiply(t *testing.T) {
+	expect(t, Identity.Multiply(Identity) == Identity)
+	m := Scaling(2, 2).Multiply(Translate(1, 0))
+	expect(t, m.Apply(P(1, 1)) == P(3, 2))
+	m = Translate(1, 0).Multiply(Scaling(2, 2))
+	expect(t, m.Apply(P(1, 1)) == P(4, 2))
+}
+
+func TestSeriesTransform(t *testing.T) {
+	rot := Rotation(math.Pi / 3)
+	tr := Translate(60, 70)
+	for _, shape := range [][]Point{octagon, concave1, bowtie, AZ} {
+		series := makeSeries(shape, true, true, DefaultIndexOptions)
+		series2 := series.Transform(rot).(*baseSeries).Transform(tr)
+		series3 := series.Transform(rot.Multiply(tr))
+		expect(t, series2.NumPoints() == len(shape))
+		expect(t, series3.NumPoints() == len(shape))
+		for i := 0; i < len(shape); i++ {
+			expect(t, pointsNear(series2.PointAt(i), series3.PointAt(i), 1e-9))
+		}
+		expect(t, pointsNear(series2.Rect().Min, series3.Rect().Min, 1e-9))
+		expect(t, pointsNear(series2.Rect().Max, series3.Rect().Max, 1e-9))
+		expect(t, (len(series.Index()) > 0) == (len(series3.Index()) > 0))
+		expect(t, series3.Clockwise() == series.Clockwise())
+		expect(t, series3.Convex() == series.Convex())
+
+		// translation alone must match Move
+		series4 := series.Transform(tr)
+		series5 := series.Move(60, 70)
+		for i := 0; i < len(shape); i++ {
+			expect(t, series4.PointAt(i) == series5.PointAt(i))
+		}
+		expect(t, series4.Rect() == series5.Rect())
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// The binary series format is:
+//
+//	magic    [4]byte  "GSER"
+//	version  byte
+//	flags    byte     closed, clockwise, convex, delta, bulk load
+//	kind     byte     IndexKind
+//	rect     [4]float64
+//	count    uint32   number of points
+//	points   [count][2]float64, or when the delta flag is set:
+//	         precision byte, followed by [count][2]varint
+//	options  [3]uint32 MinPoints, MaxNodeItems, MaxDepth of the index
+//	size     uint32   number of index bytes
+//	index    [size]byte
+//
+// All numbers are little-endian. Version 1 does not have the options, and
+// series parsed from it use the default index options.
+const (
+	binaryMagic      = "GSER"
+	binaryVersion    = 2
+	binaryHeaderSize = 4 + 1 + 1 + 1 + 32 + 4
+)
+
+const (
+	binaryClosed    = 1 << 0
+	binaryClockwise = 1 << 1
+	binaryConvex    = 1 << 2
+	binaryDelta     = 1 << 3
+	binaryBulkLoad  = 1 << 4
+)
+
+// BinaryOptions are options for encoding a series with AppendBinary.
+type BinaryOptions struct {
+	// Delta stores each point as the difference from the previous point,
+	// after scaling the coordinates to integers using Precision. The
+	// differences are zigzag varints, which usually take two or three bytes
+	// for dense data like GPS tracks, instead of the eight bytes of a
+	// float64.
+	// The tradeoff is accuracy, because points are rounded to the nearest
+	// 10^-Precision. For longitude and latitude, a precision of 5 is about
+	// one meter and 7 is about one centimeter.
+	Delta bool
+	// Precision is the number of decimal digits kept when Delta is true.
+	// It's clamped to between 0 and 15, and lowered further when the
+	// scaled coordinates would overflow the integers. Points that cannot be
+	// scaled at any precision, such as very large or non-finite numbers,
+	// are stored as float64 pairs instead.
+	Precision int
+}
+
+// DefaultBinaryOptions stores points as float64 pairs.
+var DefaultBinaryOptions = &BinaryOptions{Delta: false}
+
+// ErrInvalidBinary is returned when parsing malformed series binary data.
+var ErrInvalidBinary = errors.New("invalid series binary")
+
+func appendUint32(dst []byte, n uint32) []byte {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], n)
+	return append(dst, buf[:]...)
+}
+
+func appendFloat64(dst []byte, f float64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+	return append(dst, buf[:]...)
+}
+
+func readFloat64(data []byte) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(data))
+}
+
+// AppendBinary appends the binary representation of the series to dst and
+// returns the extended buffer. The encoding includes the points, the flags,
+// and the compressed index, so the series can be restored with
+// ParseSeriesBinary without processing the points or rebuilding the index.
+//
+// When opts.Delta is true, the points are rounded to opts.Precision before
+// being encoded, and the flags and index are those of the rounded points.
+func (series *baseSeries) AppendBinary(dst []byte, opts *BinaryOptions) []byte {
+	if opts == nil {
+		opts = DefaultBinaryOptions
+	}
+	precision := -1
+	if opts.Delta {
+		precision = deltaPrecision(series.points, opts.Precision)
+	}
+	if precision >= 0 {
+		scale := math.Pow10(precision)
+		points := make([]Point, len(series.points))
+		for i, point := range series.points {
+			points[i].X = math.Round(point.X*scale) / scale
+			points[i].Y = math.Round(point.Y*scale) / scale
+		}
+		rounded := makeSeries(points, false, series.closed, NoIndexing)
+		rounded.indexKind = series.indexKind
+		rounded.indexOpts = series.indexOpts
+		if len(series.index) > 0 {
+			rounded.buildIndex()
+		}
+		series = &rounded
+		dst = series.appendBinaryHeader(dst, binaryDelta)
+		dst = append(dst, byte(precision))
+		var buf [binary.MaxVarintLen64]byte
+		var px, py int64
+		for _, point := range series.points {
+			x, y := int64(math.Round(point.X*scale)), int64(math.Round(point.Y*scale))
+			dst = append(dst, buf[:binary.PutVarint(buf[:], x-px)]...)
+			dst = append(dst, buf[:binary.PutVarint(buf[:], y-py)]...)
+			px, py = x, y
+		}
+	} else {
+		dst = series.appendBinaryHeader(dst, 0)
+		for _, point := range series.points {
+			dst = appendFloat64(dst, point.X)
+			dst = appendFloat64(dst, point.Y)
+		}
+	}
+	dst = appendUint32(dst, binaryOption(series.indexOpts.MinPoints))
+	dst = appendUint32(dst, binaryOption(series.indexOpts.MaxNodeItems))
+	dst = appendUint32(dst, binaryOption(series.indexOpts.MaxDepth))
+	dst = appendUint32(dst, uint32(len(series.index)))
+	return append(dst, series.index...)
+}
+
+// binaryOption returns an index option as a uint32, with zero for the
+// options that are negative, which are treated as the default anyway.
+func binaryOption(n int) uint32 {
+	if n < 0 {
+		return 0
+	}
+	if n > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(n)
+}
+
+// binaryMaxDelta is the largest scaled coordinate for the delta encoding.
+// The difference of two coordinates must also fit in an int64.
+const binaryMaxDelta = 1 << 62
+
+// deltaPrecision returns the clamped precision that can scale all of the
+// points to integers without overflowing, or -1 if there isn't one.
+func deltaPrecision(points []Point, precision int) int {
+	if precision < 0 {
+		precision = 0
+	} else if precision > 15 {
+		precision = 15
+	}
+	var max float64
+	for _, point := range points {
+		max = math.Max(max, math.Max(math.Abs(point.X), math.Abs(point.Y)))
+	}
+	if math.IsNaN(max) {
+		return -1
+	}
+	for ; precision >= 0; precision-- {
+		if math.Round(max*math.Pow10(precision)) < binaryMaxDelta {
+			break
+		}
+	}
+	return precision
+}
+
+func (series *baseSeries) appendBinaryHeader(dst []byte, flags byte) []byte {
+	if series.closed {
+		flags |= binaryClosed
+	}
+	if series.clockwise {
+		flags |= binaryClockwise
+	}
+	if series.convex {
+		flags |= binaryConvex
+	}
+	if series.indexOpts.BulkLoad {
+		flags |= binaryBulkLoad
+	}
+	dst = append(dst, binaryMagic...)
+	dst = append(dst, binaryVersion, flags, byte(series.indexKind))
+	dst = appendFloat64(dst, series.rect.Min.X)
+	dst = appendFloat64(dst, series.rect.Min.Y)
+	dst = appendFloat64(dst, series.rect.Max.X)
+	dst = appendFloat64(dst, series.rect.Max.Y)
+	return appendUint32(dst, uint32(len(series.points)))
+}
+
+// ParseSeriesBinary returns a series from data that was created with
+// AppendBinary. The closed param must match the series that was encoded.
+// The index is checked to be well formed and to only refer to segments of
+// the series. The returned series shares the index bytes with data, which
+// must not be modified for as long as the series is in use.
+//
+// The index keeps the IndexOptions that it was built with, so the series
+// rebuilds the same index after a Recompute, Move, or Transform.
+func ParseSeriesBinary(data []byte, closed bool) (Series, error) {
+	if len(data) < binaryHeaderSize || string(data[:4]) != binaryMagic ||
+		data[4] < 1 || data[4] > binaryVersion {
+		return nil, ErrInvalidBinary
+	}
+	version, flags := data[4], data[5]
+	if (flags&binaryClosed != 0) != closed {
+		return nil, ErrInvalidBinary
+	}
+	series := &baseSeries{
+		closed:    closed,
+		clockwise: flags&binaryClockwise != 0,
+		convex:    flags&binaryConvex != 0,
+		indexKind: IndexKind(data[6]),
+	}
+	series.rect.Min.X = readFloat64(data[7:])
+	series.rect.Min.Y = readFloat64(data[15:])
+	series.rect.Max.X = readFloat64(data[23:])
+	series.rect.Max.Y = readFloat64(data[31:])
+	count := int(binary.LittleEndian.Uint32(data[39:]))
+	data = data[binaryHeaderSize:]
+	if flags&binaryDelta != 0 {
+		// each point is at least two bytes
+		if len(data) < 1 || count > (len(data)-1)/2 || data[0] > 15 {
+			return nil, ErrInvalidBinary
+		}
+		scale := math.Pow10(int(data[0]))
+		data = data[1:]
+		series.points = make([]Point, count)
+		var x, y int64
+		for i := range series.points {
+			dx, n := binary.Varint(data)
+			if n <= 0 {
+				return nil, ErrInvalidBinary
+			}
+			dy, m := binary.Varint(data[n:])
+			if m <= 0 {
+				return nil, ErrInvalidBinary
+			}
+			data = data[n+m:]
+			x, y = x+dx, y+dy
+			series.points[i] = Point{float64(x) / scale, float64(y) / scale}
+		}
+	} else {
+		if count > len(data)/16 {
+			return nil, ErrInvalidBinary
+		}
+		series.points = make([]Point, count)
+		for i := range series.points {
+			series.points[i].X = readFloat64(data[i*16:])
+			series.points[i].Y = readFloat64(data[i*16+8:])
+		}
+		data = data[count*16:]
+	}
+	opts := IndexOptions{
+		Kind:      series.indexKind,
+		MinPoints: DefaultIndexOptions.MinPoints,
+		BulkLoad:  flags&binaryBulkLoad != 0,
+	}
+	if version >= 2 {
+		if len(data) < 12 {
+			return nil, ErrInvalidBinary
+		}
+		if n := int(binary.LittleEndian.Uint32(data)); n > 0 {
+			opts.MinPoints = n
+		}
+		opts.MaxNodeItems = int(binary.LittleEndian.Uint32(data[4:]))
+		opts.MaxDepth = int(binary.LittleEndian.Uint32(data[8:]))
+		data = data[12:]
+	}
+	if len(data) < 4 {
+		return nil, ErrInvalidBinary
+	}
+	size := int(binary.LittleEndian.Uint32(data))
+	data = data[4:]
+	if size != len(data) {
+		return nil, ErrInvalidBinary
+	}
+	if size > 0 {
+		if size < 5 || series.indexKind != QuadTree ||
+			data[0] != byte(series.indexKind) ||
+			int(binary.LittleEndian.Uint32(data[1:])) != size ||
+			!qCompressValid(data, 5, size, series.NumSegments()) {
+			return nil, ErrInvalidBinary
+		}
+		series.index = data[:size:size]
+		series.indexOpts = opts
+	}
+	return series, nil
+}
@@ -0,0 +1,245 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSeriesBinary(t *testing.T) {
+	seed := time.Now().UnixNano()
+	rng := rand.New(rand.NewSource(seed))
+	for _, opts := range []*IndexOptions{NoIndexing, DefaultIndexOptions} {
+		for _, closed := range []bool{true, false} {
+			series := makeSeries(AZ, true, closed, opts)
+			data := series.AppendBinary([]byte("prefix"), nil)
+			expect(t, string(data[:6]) == "prefix")
+			parsed, err := ParseSeriesBinary(data[6:], closed)
+			expect(t, err == nil)
+			expect(t, string(parsed.Index()) == string(series.Index()))
+			expect(t, parsed.Rect() == series.Rect())
+			expect(t, parsed.Closed() == series.Closed())
+			expect(t, parsed.Clockwise() == series.Clockwise())
+			expect(t, parsed.Convex() == series.Convex())
+			expect(t, parsed.NumPoints() == series.NumPoints())
+			for i := 0; i < series.NumPoints(); i++ {
+				expect(t, parsed.PointAt(i) == series.PointAt(i))
+			}
+			if len(series.Index()) > 0 {
+				// the parsed index points into the data
+				expect(t, &parsed.Index()[0] == &data[len(data)-len(series.Index())])
+			}
+			rect := series.Rect()
+			for i := 0; i < 100; i++ {
+				x := rect.Min.X + rng.Float64()*(rect.Max.X-rect.Min.X)
+				y := rect.Min.Y + rng.Float64()*(rect.Max.Y-rect.Min.Y)
+				r := R(x, y, x+rng.Float64(), y+rng.Float64())
+				expect(t, intsEqual(searchIndexes(t, parsed, r),
+					searchIndexes(t, &series, r)))
+			}
+			// wrong closed flag
+			_, err = ParseSeriesBinary(data[6:], !closed)
+			expect(t, err == ErrInvalidBinary)
+			// truncated
+			for _, n := range []int{0, 3, 10, 50, len(data) - 7} {
+				_, err = ParseSeriesBinary(data[6:6+n], closed)
+				expect(t, err == ErrInvalidBinary)
+			}
+		}
+	}
+	// empty
+	var empty baseSeries
+	parsed, err := ParseSeriesBinary(empty.AppendBinary(nil, nil), false)
+	expect(t, err == nil && parsed.NumPoints() == 0 && parsed.Empty())
+}
+
+func TestSeriesBinaryCorruptIndex(t *testing.T) {
+	seed := time.Now().UnixNano()
+	rng := rand.New(rand.NewSource(seed))
+	series := makeSeries(AZ, true, true, DefaultIndexOptions)
+	data := series.AppendBinary(nil, nil)
+	isize := len(series.Index())
+	body := len(data) - isize + 5
+	// every query on a series that parsed must stay within the segments
+	use := func(parsed Series) {
+		rect := parsed.Rect()
+		parsed.Search(rect, func(seg Segment, idx int) bool {
+			expect(t, idx >= 0 && idx < parsed.NumSegments())
+			return true
+		})
+		near := KNearestSegments(parsed, 10,
+			func(rect Rect) float64 { return rect.DistanceToPoint(P(-111, 34)) },
+			func(seg Segment) float64 { return seg.Distance(P(-111, 34)) })
+		expect(t, len(near) <= 10)
+		SegmentsWithinRadius(parsed, P(-111, 34), 1,
+			func(seg Segment, idx int, dist float64) bool {
+				expect(t, idx >= 0 && idx < parsed.NumSegments())
+				return true
+			})
+	}
+	for i := 0; i < 2000; i++ {
+		corrupt := append([]byte(nil), data...)
+		for j := rng.Intn(3); j >= 0; j-- {
+			corrupt[body+rng.Intn(len(data)-body)] = byte(rng.Intn(256))
+		}
+		if parsed, err := ParseSeriesBinary(corrupt, true); err == nil {
+			use(parsed)
+		} else {
+			expect(t, err == ErrInvalidBinary)
+		}
+	}
+	// an item past the last segment
+	points := []Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	small := makeSeries(points, true, true, NoIndexing)
+	bad := small.AppendBinary(nil, nil)
+	index := []byte{byte(QuadTree), 0, 0, 0, 0, 1, 4, 0}
+	binary.LittleEndian.PutUint32(index[1:], uint32(len(index)))
+	bad[6] = byte(QuadTree)
+	bad = appendUint32(bad[:len(bad)-4], uint32(len(index)))
+	_, err := ParseSeriesBinary(append(bad, index...), true)
+	expect(t, err == ErrInvalidBinary)
+	// the same index with a valid item parses
+	index[6] = 3
+	parsed, err := ParseSeriesBinary(append(bad, index...), true)
+	expect(t, err == nil)
+	use(parsed)
+	// a split flag that isn't zero or one, a trailing byte, and a quad that
+	// is larger than the index
+	for _, index := range [][]byte{
+		{byte(QuadTree), 0, 0, 0, 0, 1, 3, 2},
+		{byte(QuadTree), 0, 0, 0, 0, 1, 3, 0, 0},
+		{byte(QuadTree), 0, 0, 0, 0, 0, 1, 9, 0, 0, 0},
+	} {
+		binary.LittleEndian.PutUint32(index[1:], uint32(len(index)))
+		_, err := ParseSeriesBinary(append(bad, index...), true)
+		expect(t, err == ErrInvalidBinary)
+	}
+}
+
+func intsEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSeriesBinaryDelta(t *testing.T) {
+	for _, opts := range []*IndexOptions{NoIndexing, DefaultIndexOptions} {
+		series := makeSeries(AZ, true, true, opts)
+		dense := series.AppendBinary(nil, nil)
+		for _, precision := range []int{0, 3, 5, 7} {
+			delta := series.AppendBinary(nil,
+				&BinaryOptions{Delta: true, Precision: precision})
+			expect(t, len(delta) < len(dense))
+			parsed, err := ParseSeriesBinary(delta, true)
+			expect(t, err == nil)
+			expect(t, parsed.NumPoints() == series.NumPoints())
+			tol := 0.5/math.Pow10(precision) + 1e-12
+			for i := 0; i < series.NumPoints(); i++ {
+				p1, p2 := parsed.PointAt(i), series.PointAt(i)
+				expect(t, math.Abs(p1.X-p2.X) <= tol && math.Abs(p1.Y-p2.Y) <= tol)
+			}
+			// the parsed series is identical to one made from the rounded
+			// points.
+			rounded := makeSeries(parsed.RawPoints(), true, true, opts)
+			expect(t, string(parsed.Index()) == string(rounded.Index()))
+			expect(t, parsed.Rect() == rounded.Rect())
+			expect(t, parsed.Clockwise() == rounded.Clockwise())
+			expect(t, parsed.Convex() == rounded.Convex())
+			for i := 0; i < 100; i++ {
+				r := R(-112+float64(i)/50, 33, -111.5+float64(i)/50, 35)
+				expect(t, intsEqual(searchIndexes(t, parsed, r),
+					searchIndexes(t, &rounded, r)))
+			}
+			_, err = ParseSeriesBinary(delta[:len(delta)-len(parsed.Index())-6],
+				true)
+			expect(t, err == ErrInvalidBinary)
+		}
+		// seven decimal digits, which is about a centimeter, takes less than
+		// half of the dense size.
+		delta := series.AppendBinary(nil, &BinaryOptions{Delta: true, Precision: 7})
+		parsed, _ := ParseSeriesBinary(delta, true)
+		expect(t, len(delta)-len(parsed.Index()) <
+			(len(dense)-len(series.Index()))/2)
+	}
+}
+
+func TestSeriesBinaryDeltaOverflow(t *testing.T) {
+	expect(t, deltaPrecision(AZ, 20) == 15)
+	expect(t, deltaPrecision(AZ, -1) == 0)
+	// 10^15 * 10000 does not fit, 10^14 * 10000 does
+	expect(t, deltaPrecision([]Point{{10000, 0}, {0, -10000}}, 15) == 14)
+	expect(t, deltaPrecision([]Point{{1e19, 0}}, 5) == -1)
+	expect(t, deltaPrecision([]Point{{math.Inf(-1), 0}}, 5) == -1)
+	expect(t, deltaPrecision([]Point{{math.NaN(), 0}}, 5) == -1)
+
+	// the points are not scaled past the integers, and the opposite signs
+	// don't overflow the differences.
+	points := []Point{{12345.678, -9999.5}, {-12345.678, 9999.5},
+		{0.25, 0.125}, {12345.678, -9999.5}}
+	series := makeSeries(points, true, false, NoIndexing)
+	data := series.AppendBinary(nil, &BinaryOptions{Delta: true, Precision: 15})
+	parsed, err := ParseSeriesBinary(data, false)
+	expect(t, err == nil)
+	for i, point := range points {
+		p := parsed.PointAt(i)
+		expect(t, math.Abs(p.X-point.X) < 1e-9 && math.Abs(p.Y-point.Y) < 1e-9)
+	}
+	// too large for any precision, so the points are stored as floats
+	points = []Point{{1e300, -1e300}, {0, 0}, {-1e300, 1e300}}
+	series = makeSeries(points, true, false, NoIndexing)
+	data = series.AppendBinary(nil, &BinaryOptions{Delta: true, Precision: 5})
+	expect(t, string(data) == string(series.AppendBinary(nil, nil)))
+	parsed, err = ParseSeriesBinary(data, false)
+	expect(t, err == nil)
+	for i, point := range points {
+		expect(t, parsed.PointAt(i) == point)
+	}
+}
+
+func TestSeriesBinaryIndexOptions(t *testing.T) {
+	opts := &IndexOptions{
+		Kind: QuadTree, MinPoints: 32, MaxNodeItems: 4, MaxDepth: 5,
+		BulkLoad: true,
+	}
+	series := makeSeries(AZ, true, true, opts)
+	data := series.AppendBinary(nil, nil)
+	parsed, err := ParseSeriesBinary(data, true)
+	expect(t, err == nil)
+	expect(t, *seriesIndexOptions(parsed) == *opts)
+	// the same index is rebuilt as the original would
+	moved := series.Move(1000, 1000)
+	expect(t, string(parsed.(*baseSeries).Move(1000, 1000).Index()) ==
+		string(moved.Index()))
+	transform := Translate(0.5, 0.25).Multiply(Rotation(0.3))
+	expect(t, string(parsed.(*baseSeries).Transform(transform).Index()) ==
+		string(series.Transform(transform).Index()))
+
+	// version 1 has no options, so the defaults are used
+	i := len(data) - len(series.Index()) - 4 - 12
+	v1 := append(append([]byte(nil), data[:i]...), data[i+12:]...)
+	v1[4] = 1
+	v1[5] &^= binaryBulkLoad
+	parsed, err = ParseSeriesBinary(v1, true)
+	expect(t, err == nil)
+	expect(t, string(parsed.Index()) == string(series.Index()))
+	expect(t, *seriesIndexOptions(parsed) ==
+		IndexOptions{Kind: QuadTree, MinPoints: DefaultIndexOptions.MinPoints})
+	// unknown versions
+	for _, version := range []byte{0, binaryVersion + 1} {
+		data[4] = version
+		_, err = ParseSeriesBinary(data, true)
+		expect(t, err == ErrInvalidBinary)
+	}
+}
@@ -0,0 +1,363 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math"
+	"sort"
+)
+
+// Union returns the area that is covered by either the polygon or the other
+// polygon. The result is a MultiPoly because polygons that do not overlap
+// stay apart. When the polygons do not overlap the result has both
+// polygons, and when one polygon contains the other the result has only the
+// larger polygon.
+func (poly *Poly) Union(other *Poly) *MultiPoly {
+	return polyBoolean(poly, other, boolUnion)
+}
+
+// Intersection returns the area that is covered by both the polygon and the
+// other polygon, which may be more than one polygon. Polygons that do not
+// overlap, or that only touch, have an empty result.
+func (poly *Poly) Intersection(other *Poly) *MultiPoly {
+	return polyBoolean(poly, other, boolIntersection)
+}
+
+// Difference returns the area of the polygon that is not covered by the
+// other polygon. The result may have new holes, or the polygon may be split
+// into more than one polygon.
+func (poly *Poly) Difference(other *Poly) *MultiPoly {
+	return polyBoolean(poly, other, boolDifference)
+}
+
+// IntersectionOverUnion returns the area of the intersection of the polygons
+// divided by the area of their union, which measures how closely two
+// polygons match. The result is 1 for identical polygons, and 0 for polygons
+// that do not overlap. Returns 0 if the union has no area.
+func IntersectionOverUnion(a, b *Poly) float64 {
+	union := boolArea(a.Union(b))
+	if union <= 0 {
+		return 0
+	}
+	return math.Min(boolArea(a.Intersection(b))/union, 1)
+}
+
+// boolArea returns the total area of the polygons.
+func boolArea(mpoly *MultiPoly) float64 {
+	var area float64
+	for _, poly := range mpoly.Polys {
+		area += poly.Area()
+	}
+	return area
+}
+
+// boolOp is a boolean operation on two polygons.
+type boolOp int
+
+const (
+	boolUnion boolOp = iota
+	boolIntersection
+	boolDifference
+)
+
+// boolClass is how an edge of one polygon relates to the other polygon.
+type boolClass int
+
+const (
+	boolOutside  boolClass = iota // edge is outside of the other polygon
+	boolInside                    // edge is inside of the other polygon
+	boolSame                      // shared edge, with the same direction
+	boolOpposite                  // shared edge, with the opposite direction
+)
+
+// boolEdge is a directed edge with the interior of its polygon on the left.
+type boolEdge struct {
+	seg   Segment
+	fromA bool
+	class boolClass
+}
+
+// selectEdge returns true if the edge is part of the result of the
+// operation, and whether it needs to be reversed. Shared edges are only
+// taken from the first polygon, so that they are not used twice.
+func (op boolOp) selectEdge(edge boolEdge) (keep, reverse bool) {
+	switch op {
+	case boolUnion:
+		return edge.class == boolOutside ||
+			(edge.class == boolSame && edge.fromA), false
+	case boolIntersection:
+		return edge.class == boolInside ||
+			(edge.class == boolSame && edge.fromA), false
+	case boolDifference:
+		if edge.fromA {
+			return edge.class == boolOutside || edge.class == boolOpposite,
+				false
+		}
+		return edge.class == boolInside, true
+	}
+	return false, false
+}
+
+// polyBoolean performs the boolean operation on the polygons, in the
+// manner of the Weiler–Atherton algorithm. The points where the rings of
+// the polygons intersect are inserted into both polygons, and each edge
+// between those points is classified as being inside of, outside of, or
+// shared with the other polygon. The edges that are selected by the
+// operation are then traced into the rings of the result.
+func polyBoolean(a, b *Poly, op boolOp) *MultiPoly {
+	var polys []*Poly
+	switch {
+	case a.Empty() && b.Empty():
+	case a.Empty():
+		if op == boolUnion {
+			polys = append(polys, b)
+		}
+	case b.Empty():
+		if op != boolIntersection {
+			polys = append(polys, a)
+		}
+	case !a.Rect().IntersectsRect(b.Rect()):
+		switch op {
+		case boolUnion:
+			polys = append(polys, a, b)
+		case boolDifference:
+			polys = append(polys, a)
+		}
+	default:
+		edges := boolEdges(a, b)
+		var selected []Segment
+		for _, edge := range edges {
+			keep, reverse := op.selectEdge(edge)
+			if !keep {
+				continue
+			}
+			seg := edge.seg
+			if reverse {
+				seg.A, seg.B = seg.B, seg.A
+			}
+			selected = append(selected, seg)
+		}
+		polys = boolPolys(boolTrace(selected),
+			seriesIndexOptions(a.Exterior))
+	}
+	return NewMultiPoly(polys)
+}
+
+// polyRings returns the rings of the polygon, with the exterior first.
+func polyRings(poly *Poly) []Ring {
+	return append([]Ring{poly.Exterior}, poly.Holes...)
+}
+
+// boolEdges splits the rings of both polygons at the points where they
+// intersect, and returns the classified edges.
+func boolEdges(a, b *Poly) []boolEdge {
+	aRings, bRings := polyRings(a), polyRings(b)
+	aSplits := make([][][]Point, len(aRings))
+	bSplits := make([][][]Point, len(bRings))
+	for i, ring := range aRings {
+		aSplits[i] = make([][]Point, ring.NumSegments())
+	}
+	for i, ring := range bRings {
+		bSplits[i] = make([][]Point, ring.NumSegments())
+	}
+	// Each intersection point is calculated once and added to both edges,
+	// which makes the edges that are shared by both polygons identical.
+	var points []Point
+	for i, aRing := range aRings {
+		for j := range aSplits[i] {
+			aseg := aRing.SegmentAt(j)
+			for k, bRing := range bRings {
+				bRing.Search(aseg.Rect(), func(bseg Segment, l int) bool {
+					points = appendIntersectionPoints(points[:0], aseg, bseg)
+					aSplits[i][j] = append(aSplits[i][j], points...)
+					bSplits[k][l] = append(bSplits[k][l], points...)
+					return true
+				})
+			}
+		}
+	}
+	aEdges := boolSplitRings(aRings, aSplits, true)
+	bEdges := boolSplitRings(bRings, bSplits, false)
+	bSet := make(map[Segment]bool, len(bEdges))
+	for _, edge := range bEdges {
+		bSet[edge.seg] = true
+	}
+	aSet := make(map[Segment]bool, len(aEdges))
+	for i, edge := range aEdges {
+		aSet[edge.seg] = true
+		aEdges[i].class = boolClassify(edge.seg, b, bSet)
+	}
+	for i, edge := range bEdges {
+		bEdges[i].class = boolClassify(edge.seg, a, aSet)
+	}
+	return append(aEdges, bEdges...)
+}
+
+// boolSplitRings splits each ring segment at its points, and returns the
+// edges with the exterior counter-clockwise and the holes clockwise.
+func boolSplitRings(rings []Ring, splits [][][]Point, fromA bool,
+) []boolEdge {
+	var edges []boolEdge
+	for i, ring := range rings {
+		var ringEdges []boolEdge
+		for j, points := range splits[i] {
+			seg := ring.SegmentAt(j)
+			if seg.A == seg.B {
+				continue
+			}
+			points = append(points, seg.A, seg.B)
+			sort.Slice(points, func(k, l int) bool {
+				return seg.ClosestParameter(points[k]) <
+					seg.ClosestParameter(points[l])
+			})
+			for k := 1; k < len(points); k++ {
+				if points[k] != points[k-1] {
+					ringEdges = append(ringEdges, boolEdge{
+						seg:   Segment{points[k-1], points[k]},
+						fromA: fromA,
+					})
+				}
+			}
+		}
+		if ring.Clockwise() != (i > 0) {
+			for j := range ringEdges {
+				seg := &ringEdges[j].seg
+				seg.A, seg.B = seg.B, seg.A
+			}
+		}
+		edges = append(edges, ringEdges...)
+	}
+	return edges
+}
+
+// boolClassify returns how the edge relates to the other polygon, where
+// otherEdges is the set of edges of the other polygon.
+func boolClassify(seg Segment, other *Poly, otherEdges map[Segment]bool,
+) boolClass {
+	switch {
+	case otherEdges[seg]:
+		return boolSame
+	case otherEdges[Segment{seg.B, seg.A}]:
+		return boolOpposite
+	case other.ContainsPoint(Point{(seg.A.X + seg.B.X) / 2,
+		(seg.A.Y + seg.B.Y) / 2}):
+		return boolInside
+	}
+	return boolOutside
+}
+
+// boolTrace links the edges into closed rings. Where more than one edge
+// leaves a point, the edge that turns the most to the left is followed,
+// which keeps the rings as small as possible. Edges that do not make a
+// closed ring are dropped.
+func boolTrace(edges []Segment) [][]Point {
+	starts := make(map[Point][]int)
+	for i, seg := range edges {
+		starts[seg.A] = append(starts[seg.A], i)
+	}
+	used := make([]bool, len(edges))
+	var rings [][]Point
+	for first := range edges {
+		if used[first] {
+			continue
+		}
+		used[first] = true
+		ring := []Point{edges[first].A}
+		curr := first
+		for {
+			seg := edges[curr]
+			ring = append(ring, seg.B)
+			back := math.Atan2(seg.A.Y-seg.B.Y, seg.A.X-seg.B.X)
+			next, best := -1, math.Inf(+1)
+			for _, i := range starts[seg.B] {
+				if used[i] && i != first {
+					continue
+				}
+				out := edges[i]
+				angle := back - math.Atan2(out.B.Y-out.A.Y, out.B.X-out.A.X)
+				for angle <= 0 {
+					angle += 2 * math.Pi
+				}
+				if angle < best {
+					next, best = i, angle
+				}
+			}
+			if next == -1 || next == first {
+				if next == first {
+					rings = append(rings, ring)
+				}
+				break
+			}
+			used[next] = true
+			curr = next
+		}
+	}
+	return rings
+}
+
+// boolPolys returns the polygons for the traced rings. Counter-clockwise
+// rings are exteriors and clockwise rings are holes, where each hole belongs
+// to the smallest exterior that contains it. Collinear points are removed.
+func boolPolys(rings [][]Point, opts *IndexOptions) []*Poly {
+	type exterior struct {
+		ring  Ring
+		area  float64
+		holes []Ring
+	}
+	var exteriors []*exterior
+	var holes []Ring
+	for _, points := range rings {
+		points = boolRemoveCollinear(points)
+		area := signedArea(points)
+		switch {
+		case area > 0:
+			exteriors = append(exteriors,
+				&exterior{ring: newRing(points, opts), area: area})
+		case area < 0:
+			holes = append(holes, newRing(points, opts))
+		}
+	}
+	sort.SliceStable(exteriors, func(i, j int) bool {
+		return exteriors[i].area < exteriors[j].area
+	})
+	for _, hole := range holes {
+		seg := hole.SegmentAt(0)
+		mid := Point{(seg.A.X + seg.B.X) / 2, (seg.A.Y + seg.B.Y) / 2}
+		for _, ext := range exteriors {
+			if ext.ring.Rect().ContainsRect(hole.Rect()) &&
+				ringContainsPoint(ext.ring, mid, true).hit {
+				ext.holes = append(ext.holes, hole)
+				break
+			}
+		}
+	}
+	polys := make([]*Poly, len(exteriors))
+	for i, ext := range exteriors {
+		polys[i] = &Poly{Exterior: ext.ring, Holes: ext.holes}
+	}
+	return polys
+}
+
+// boolRemoveCollinear returns the closed ring without the points that are
+// in the middle of a straight edge.
+func boolRemoveCollinear(points []Point) []Point {
+	points = points[:len(points)-1]
+	var ring []Point
+	for i, point := range points {
+		prev := points[(i+len(points)-1)%len(points)]
+		next := points[(i+1)%len(points)]
+		cross := (point.X-prev.X)*(next.Y-prev.Y) -
+			(point.Y-prev.Y)*(next.X-prev.X)
+		dot := (point.X-prev.X)*(next.X-point.X) +
+			(point.Y-prev.Y)*(next.Y-point.Y)
+		if cross != 0 || dot <= 0 {
+			ring = append(ring, point)
+		}
+	}
+	if len(ring) < 3 {
+		return nil
+	}
+	return append(ring, ring[0])
+}
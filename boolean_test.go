@@ -0,0 +1,203 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math"
+	"testing"
+)
+
+func multiPolyArea(mpoly *MultiPoly) float64 {
+	var area float64
+	for _, poly := range mpoly.Polys {
+		area += poly.Area()
+	}
+	return area
+}
+
+func rectPoly(rect Rect) *Poly {
+	return &Poly{Exterior: rect}
+}
+
+func TestPolyUnion(t *testing.T) {
+	// L-shape
+	a := rectPoly(R(0, 0, 2, 1))
+	b := rectPoly(R(0, 0, 1, 2))
+	mpoly := a.Union(b)
+	expect(t, len(mpoly.Polys) == 1)
+	expect(t, mpoly.Polys[0].Exterior.NumPoints() == 7)
+	expect(t, len(mpoly.Polys[0].Holes) == 0)
+	expect(t, multiPolyArea(mpoly) == 3)
+	expect(t, mpoly.ContainsPoint(P(1.5, 0.5)))
+	expect(t, mpoly.ContainsPoint(P(0.5, 1.5)))
+	expect(t, !mpoly.ContainsPoint(P(1.5, 1.5)))
+	// overlapping squares, area is the sum minus the intersection
+	dualPolyTest(t, []Point{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}, nil,
+		func(t *testing.T, a *Poly) {
+			b := NewPoly([]Point{{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}},
+				nil, nil)
+			mpoly := a.Union(b)
+			expect(t, len(mpoly.Polys) == 1)
+			expect(t, mpoly.Polys[0].Exterior.NumPoints() == 9)
+			expect(t, multiPolyArea(mpoly) == 4+4-1)
+			expect(t, multiPolyArea(b.Union(a)) == 4+4-1)
+		})
+	// no overlap
+	c := rectPoly(R(5, 5, 6, 6))
+	mpoly = a.Union(c)
+	expect(t, len(mpoly.Polys) == 2)
+	expect(t, mpoly.Polys[0] == a && mpoly.Polys[1] == c)
+	// overlapping rects but disjoint polygons
+	d := NewPoly([]Point{{0, 3}, {3, 0}, {3, 3}, {0, 3}}, nil, nil)
+	e := NewPoly([]Point{{0, 0}, {2, 0}, {0, 2}, {0, 0}}, nil, nil)
+	mpoly = d.Union(e)
+	expect(t, len(mpoly.Polys) == 2)
+	expect(t, multiPolyArea(mpoly) == d.Area()+e.Area())
+	// containment
+	big := rectPoly(R(0, 0, 10, 10))
+	small := rectPoly(R(2, 2, 4, 4))
+	for _, mpoly := range []*MultiPoly{big.Union(small), small.Union(big)} {
+		expect(t, len(mpoly.Polys) == 1)
+		expect(t, mpoly.Polys[0].Exterior.Rect() == R(0, 0, 10, 10))
+		expect(t, multiPolyArea(mpoly) == 100)
+	}
+	// a union that encloses a hole
+	u := NewPoly([]Point{{0, 0}, {3, 0}, {3, 1}, {1, 1}, {1, 2}, {3, 2},
+		{3, 3}, {0, 3}, {0, 0}}, nil, nil)
+	bar := rectPoly(R(2, 0, 3, 3))
+	mpoly = u.Union(bar)
+	expect(t, len(mpoly.Polys) == 1)
+	expect(t, len(mpoly.Polys[0].Holes) == 1)
+	expect(t, multiPolyArea(mpoly) == 8)
+	expect(t, !mpoly.ContainsPoint(P(1.5, 1.5)))
+	// holes are kept where they are not covered
+	donut := NewPoly([]Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		[][]Point{{{2, 2}, {8, 2}, {8, 8}, {2, 8}, {2, 2}}}, nil)
+	mpoly = donut.Union(rectPoly(R(1, 4, 5, 6)))
+	expect(t, len(mpoly.Polys) == 1)
+	expect(t, len(mpoly.Polys[0].Holes) == 1)
+	expect(t, math.Abs(multiPolyArea(mpoly)-(100-36+6)) < 1e-9)
+	// empty
+	var empty *Poly
+	expect(t, len(empty.Union(empty).Polys) == 0)
+	expect(t, a.Union(empty).Polys[0] == a)
+	expect(t, empty.Union(a).Polys[0] == a)
+}
+
+func TestPolyIntersection(t *testing.T) {
+	dualPolyTest(t, []Point{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}, nil,
+		func(t *testing.T, a *Poly) {
+			b := NewPoly([]Point{{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}},
+				nil, nil)
+			for _, mpoly := range []*MultiPoly{
+				a.Intersection(b), b.Intersection(a),
+			} {
+				expect(t, len(mpoly.Polys) == 1)
+				expect(t, mpoly.Polys[0].Exterior.NumPoints() == 5)
+				expect(t, mpoly.Polys[0].Rect() == R(1, 1, 2, 2))
+				expect(t, multiPolyArea(mpoly) == 1)
+			}
+		})
+	// touching only
+	a := rectPoly(R(0, 0, 1, 1))
+	expect(t, len(a.Intersection(rectPoly(R(1, 0, 2, 1))).Polys) == 0)
+	expect(t, len(a.Intersection(rectPoly(R(1, 1, 2, 2))).Polys) == 0)
+	// disjoint
+	expect(t, len(a.Intersection(rectPoly(R(5, 5, 6, 6))).Polys) == 0)
+	// containment
+	big := rectPoly(R(0, 0, 10, 10))
+	small := rectPoly(R(2, 2, 4, 4))
+	for _, mpoly := range []*MultiPoly{
+		big.Intersection(small), small.Intersection(big),
+	} {
+		expect(t, len(mpoly.Polys) == 1)
+		expect(t, mpoly.Polys[0].Rect() == R(2, 2, 4, 4))
+	}
+	// identical
+	mpoly := a.Intersection(rectPoly(R(0, 0, 1, 1)))
+	expect(t, len(mpoly.Polys) == 1 && multiPolyArea(mpoly) == 1)
+	// a bar across a hole makes two pieces
+	donut := NewPoly([]Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		[][]Point{{{2, 2}, {8, 2}, {8, 8}, {2, 8}, {2, 2}}}, nil)
+	mpoly = donut.Intersection(rectPoly(R(4, -1, 6, 11)))
+	expect(t, len(mpoly.Polys) == 2)
+	expect(t, multiPolyArea(mpoly) == 8)
+	// empty
+	var empty *Poly
+	expect(t, len(a.Intersection(empty).Polys) == 0)
+	expect(t, len(empty.Intersection(a).Polys) == 0)
+}
+
+func TestPolyDifference(t *testing.T) {
+	dualPolyTest(t, []Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		nil, func(t *testing.T, big *Poly) {
+			// inside makes a hole
+			mpoly := big.Difference(rectPoly(R(2, 2, 4, 4)))
+			expect(t, len(mpoly.Polys) == 1)
+			expect(t, len(mpoly.Polys[0].Holes) == 1)
+			expect(t, multiPolyArea(mpoly) == 100-4)
+			expect(t, !mpoly.ContainsPoint(P(3, 3)))
+			expect(t, mpoly.ContainsPoint(P(1, 1)))
+			// over the edge makes a notch
+			mpoly = big.Difference(rectPoly(R(8, 4, 12, 6)))
+			expect(t, len(mpoly.Polys) == 1)
+			expect(t, len(mpoly.Polys[0].Holes) == 0)
+			expect(t, mpoly.Polys[0].Exterior.NumPoints() == 9)
+			expect(t, multiPolyArea(mpoly) == 100-4)
+			// along the edge also makes a notch
+			mpoly = big.Difference(rectPoly(R(0, 4, 2, 6)))
+			expect(t, len(mpoly.Polys) == 1)
+			expect(t, len(mpoly.Polys[0].Holes) == 0)
+			expect(t, multiPolyArea(mpoly) == 100-4)
+			// across splits in two
+			mpoly = big.Difference(rectPoly(R(4, -1, 6, 11)))
+			expect(t, len(mpoly.Polys) == 2)
+			expect(t, multiPolyArea(mpoly) == 100-20)
+			// everything
+			expect(t, len(big.Difference(rectPoly(R(-1, -1, 11, 11))).
+				Polys) == 0)
+			expect(t, len(big.Difference(big).Polys) == 0)
+		})
+	a := rectPoly(R(0, 0, 1, 1))
+	// disjoint and touching
+	for _, other := range []*Poly{
+		rectPoly(R(5, 5, 6, 6)), rectPoly(R(1, 0, 2, 1)),
+	} {
+		mpoly := a.Difference(other)
+		expect(t, len(mpoly.Polys) == 1)
+		expect(t, multiPolyArea(mpoly) == 1)
+	}
+	// subtracting a donut leaves what was in the hole
+	donut := NewPoly([]Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		[][]Point{{{2, 2}, {8, 2}, {8, 8}, {2, 8}, {2, 2}}}, nil)
+	mpoly := rectPoly(R(1, 1, 5, 5)).Difference(donut)
+	expect(t, len(mpoly.Polys) == 1)
+	expect(t, mpoly.Polys[0].Rect() == R(2, 2, 5, 5))
+	expect(t, multiPolyArea(mpoly) == 9)
+	// empty
+	var empty *Poly
+	expect(t, a.Difference(empty).Polys[0] == a)
+	expect(t, len(empty.Difference(a).Polys) == 0)
+}
+
+func TestIntersectionOverUnion(t *testing.T) {
+	a := rectPoly(R(0, 0, 10, 10))
+	// identical
+	expect(t, IntersectionOverUnion(a, rectPoly(R(0, 0, 10, 10))) == 1)
+	expect(t, IntersectionOverUnion(a, a) == 1)
+	// half overlapping
+	b := rectPoly(R(5, 0, 15, 10))
+	expect(t, math.Abs(IntersectionOverUnion(a, b)-1.0/3) < 1e-12)
+	expect(t, IntersectionOverUnion(a, b) == IntersectionOverUnion(b, a))
+	// contained
+	c := rectPoly(R(0, 0, 5, 5))
+	expect(t, math.Abs(IntersectionOverUnion(a, c)-0.25) < 1e-12)
+	// disjoint and touching
+	expect(t, IntersectionOverUnion(a, rectPoly(R(20, 20, 30, 30))) == 0)
+	expect(t, IntersectionOverUnion(a, rectPoly(R(10, 0, 20, 10))) == 0)
+	// empty
+	expect(t, IntersectionOverUnion(new(Poly), new(Poly)) == 0)
+	expect(t, IntersectionOverUnion(a, new(Poly)) == 0)
+}
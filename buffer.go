@@ -0,0 +1,177 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import "math"
+
+// JoinStyle is how the offset edges are joined at the corners of a buffer.
+type JoinStyle int
+
+// JoinStyle types
+const (
+	Round JoinStyle = iota // circular arc around the corner
+	Miter                  // sharp corner where the offset edges meet
+	Bevel                  // straight edge across the corner
+)
+
+func (join JoinStyle) String() string {
+	switch join {
+	default:
+		return "Unknown"
+	case Round:
+		return "Round"
+	case Miter:
+		return "Miter"
+	case Bevel:
+		return "Bevel"
+	}
+}
+
+// bufferRoundSegments is the number of segments in a full circle of a Round
+// join.
+const bufferRoundSegments = 32
+
+// BufferStyled returns the polygon with every edge moved outward by radius,
+// or inward for a negative radius. The holes shrink as the exterior grows.
+// Where the moved edges leave a gap at a corner, the gap is filled using the
+// join style. A Miter join is replaced with a Bevel join when the distance
+// from the corner to the tip of the miter is more than miterLimit times the
+// radius, and a miterLimit less than one is treated as one. Holes that close
+// up are removed, and an empty polygon is returned when the exterior closes
+// up. The rings are offset one at a time, so a radius that is large compared
+// to the concave features of the polygon can produce rings that intersect
+// themselves or each other.
+func BufferStyled(poly *Poly, radius float64, join JoinStyle,
+	miterLimit float64) *Poly {
+	if poly.Empty() {
+		return new(Poly)
+	}
+	miterLimit = math.Max(miterLimit, 1)
+	exterior := bufferRing(seriesCopyPoints(poly.Exterior), true, radius,
+		join, miterLimit)
+	if exterior == nil {
+		return new(Poly)
+	}
+	var holes [][]Point
+	for _, hole := range poly.Holes {
+		points := bufferRing(seriesCopyPoints(hole), false, radius, join,
+			miterLimit)
+		if points != nil {
+			holes = append(holes, points)
+		}
+	}
+	return NewPoly(exterior, holes, seriesIndexOptions(poly.Exterior))
+}
+
+// bufferRing returns the closed ring with its edges moved to the right by
+// radius, where the ring is first put in counter-clockwise order, or
+// clockwise when ccw is false. Returns nil if the ring closes up.
+func bufferRing(points []Point, ccw bool, radius float64, join JoinStyle,
+	miterLimit float64) []Point {
+	// remove repeated points, including the closing point
+	var ring []Point
+	for i, point := range points {
+		if i == 0 || point != ring[len(ring)-1] {
+			ring = append(ring, point)
+		}
+	}
+	for len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+		ring = ring[:len(ring)-1]
+	}
+	area := signedArea(ring)
+	if len(ring) < 3 || area == 0 {
+		return nil
+	}
+	if (area > 0) != ccw {
+		for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+			ring[i], ring[j] = ring[j], ring[i]
+		}
+	}
+	if radius == 0 {
+		return append(ring, ring[0])
+	}
+	n := len(ring)
+	var out []Point
+	starts := make([]int, n) // first point of each corner in out
+	for i, v := range ring {
+		starts[i] = len(out)
+		prev, next := ring[(i+n-1)%n], ring[(i+1)%n]
+		n1 := Segment{prev, v}.Normal()
+		n2 := Segment{v, next}.Normal()
+		d1 := Point{v.X - prev.X, v.Y - prev.Y}
+		d2 := Point{next.X - v.X, next.Y - v.Y}
+		cross := d1.X*d2.Y - d1.Y*d2.X
+		p1 := Point{v.X - radius*n1.X, v.Y - radius*n1.Y}
+		p2 := Point{v.X - radius*n2.X, v.Y - radius*n2.Y}
+		cos := n1.Dot(n2)
+		if cross*radius < 0 || (cross == 0 && d1.Dot(d2) > 0) {
+			// The moved edges overlap at the corner, or continue in a
+			// straight line, and are cut where they meet.
+			out = append(out, bufferMiterPoint(v, n1, n2, radius))
+			continue
+		}
+		// the moved edges leave a gap at the corner
+		switch join {
+		case Miter:
+			if 1+cos > 0 && math.Sqrt(2/(1+cos)) <= miterLimit {
+				out = append(out, bufferMiterPoint(v, n1, n2, radius))
+				continue
+			}
+		case Round:
+			u := Point{p1.X - v.X, p1.Y - v.Y}
+			w := Point{p2.X - v.X, p2.Y - v.Y}
+			sweep := math.Atan2(u.X*w.Y-u.Y*w.X, u.Dot(w))
+			if cross == 0 {
+				// turning back on itself, so go around the outside
+				sweep = math.Copysign(math.Pi, radius)
+			}
+			steps := int(math.Ceil(math.Abs(sweep) /
+				(2 * math.Pi / bufferRoundSegments)))
+			out = append(out, p1)
+			for k := 1; k < steps; k++ {
+				sin, cos := math.Sincos(sweep * float64(k) / float64(steps))
+				out = append(out, Point{
+					v.X + u.X*cos - u.Y*sin,
+					v.Y + u.X*sin + u.Y*cos,
+				})
+			}
+			out = append(out, p2)
+			continue
+		}
+		out = append(out, p1, p2)
+	}
+	out = append(out, out[0])
+	// A ring that closes up turns inside out, where either the winding
+	// changes, or every edge changes direction.
+	if area := signedArea(out); area == 0 || (area > 0) != ccw {
+		return nil
+	}
+	reversed := true
+	for i := 0; i < n && reversed; i++ {
+		// the edge from the last point of the corner to the first point of
+		// the next corner
+		a, b := out[len(out)-2], out[0]
+		if i < n-1 {
+			a, b = out[starts[i+1]-1], out[starts[i+1]]
+		}
+		next := ring[(i+1)%n]
+		edge := Point{b.X - a.X, b.Y - a.Y}
+		reversed = edge.Dot(Point{next.X - ring[i].X, next.Y - ring[i].Y}) <= 0
+	}
+	if reversed {
+		return nil
+	}
+	return out
+}
+
+// bufferMiterPoint returns the point where the edges before and after the
+// corner meet after they are moved to the right by radius.
+func bufferMiterPoint(corner, n1, n2 Point, radius float64) Point {
+	scale := radius / (1 + n1.Dot(n2))
+	return Point{
+		corner.X - scale*(n1.X+n2.X),
+		corner.Y - scale*(n1.Y+n2.Y),
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math"
+	"testing"
+)
+
+func TestJoinStyle(t *testing.T) {
+	expect(t, Round.String() == "Round")
+	expect(t, Miter.String() == "Miter")
+	expect(t, Bevel.String() == "Bevel")
+	expect(t, JoinStyle(100).String() == "Unknown")
+}
+
+func rectsNear(a, b Rect) bool {
+	return pointsNear(a.Min, b.Min, 1e-9) && pointsNear(a.Max, b.Max, 1e-9)
+}
+
+func TestBufferStyled(t *testing.T) {
+	square := NewPoly([]Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		nil, nil)
+	// bevel adds one vertex per convex corner
+	poly := BufferStyled(square, 1, Bevel, 0)
+	expect(t, poly.Exterior.NumPoints() == 8+1)
+	expect(t, math.Abs(poly.Area()-142) < 1e-9)
+	expect(t, rectsNear(poly.Exterior.Rect(), R(-1, -1, 11, 11)))
+	// miter keeps one sharp vertex per corner
+	poly = BufferStyled(square, 1, Miter, 2)
+	expect(t, poly.Exterior.NumPoints() == 4+1)
+	expect(t, math.Abs(poly.Area()-144) < 1e-9)
+	expect(t, pointsNear(poly.Exterior.PointAt(0), P(-1, -1), 1e-12))
+	// unless clamped, where the tip is sqrt(2) times the radius away
+	poly = BufferStyled(square, 1, Miter, 1.4)
+	expect(t, poly.Exterior.NumPoints() == 8+1)
+	poly = BufferStyled(square, 1, Miter, 1.5)
+	expect(t, poly.Exterior.NumPoints() == 4+1)
+	// round adds many
+	poly = BufferStyled(square, 1, Round, 0)
+	expect(t, poly.Exterior.NumPoints() > 8*4)
+	expect(t, poly.Area() > 142 && poly.Area() < 140+math.Pi)
+	for i := 0; i < poly.Exterior.NumPoints(); i++ {
+		expect(t, math.Abs(square.Exterior.Rect().DistanceToPoint(
+			poly.Exterior.PointAt(i))-1) < 1e-9)
+	}
+	// the winding of the input does not matter
+	cw := NewPoly([]Point{{0, 0}, {0, 10}, {10, 10}, {10, 0}}, nil, nil)
+	poly = BufferStyled(cw, 1, Miter, 2)
+	expect(t, !poly.Clockwise())
+	expect(t, math.Abs(poly.Area()-144) < 1e-9)
+	// concave corners are cut where the edges meet
+	ell := NewPoly([]Point{{0, 0}, {10, 0}, {10, 5}, {5, 5}, {5, 10},
+		{0, 10}, {0, 0}}, nil, nil)
+	for _, join := range []JoinStyle{Round, Miter, Bevel} {
+		poly = BufferStyled(ell, 1, join, 2)
+		expect(t, IsSimple(poly.Exterior))
+		expect(t, poly.ContainsPoint(P(5.5, 5.5)))
+		expect(t, !poly.ContainsPoint(P(6.5, 6.5)))
+	}
+	// holes shrink as the exterior grows
+	holed := NewPoly([]Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		[][]Point{{{3, 3}, {3, 7}, {7, 7}, {7, 3}, {3, 3}}}, nil)
+	poly = BufferStyled(holed, 1, Miter, 2)
+	expect(t, len(poly.Holes) == 1)
+	expect(t, rectsNear(poly.Holes[0].Rect(), R(4, 4, 6, 6)))
+	expect(t, math.Abs(poly.Area()-(144-4)) < 1e-9)
+	poly = BufferStyled(holed, 3, Miter, 2)
+	expect(t, len(poly.Holes) == 0)
+	// negative radius shrinks
+	for _, join := range []JoinStyle{Round, Miter, Bevel} {
+		poly = BufferStyled(holed, -1, join, 2)
+		expect(t, rectsNear(poly.Exterior.Rect(), R(1, 1, 9, 9)))
+		expect(t, len(poly.Holes) == 1)
+		expect(t, rectsNear(poly.Holes[0].Rect(), R(2, 2, 8, 8)))
+		expect(t, poly.Holes[0].NumPoints() > 4 || join == Miter)
+	}
+	expect(t, BufferStyled(square, -6, Miter, 2).Empty())
+	expect(t, BufferStyled(&Poly{Exterior: R(0, 0, 10, 1)}, -0.6, Bevel,
+		0).Empty())
+	// zero radius and empty
+	poly = BufferStyled(square, 0, Round, 0)
+	expect(t, poly.Area() == 100)
+	expect(t, BufferStyled(nil, 1, Round, 0).Empty())
+	expect(t, BufferStyled(new(Poly), 1, Round, 0).Empty())
+}
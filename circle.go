@@ -0,0 +1,215 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math"
+	"math/rand"
+)
+
+// pointTree is a point quadtree that answers nearest point queries.
+type pointTree struct {
+	bounds Rect
+	root   pointNode
+}
+
+type pointNode struct {
+	split  bool
+	points []Point
+	quads  [4]*pointNode
+}
+
+func newPointTree(points []Point) *pointTree {
+	tr := new(pointTree)
+	for i, point := range points {
+		if i == 0 {
+			tr.bounds = Rect{point, point}
+		} else {
+			tr.bounds = tr.bounds.Union(Rect{point, point})
+		}
+	}
+	for _, point := range points {
+		tr.root.insert(tr.bounds, point, 0)
+	}
+	return tr
+}
+
+func (n *pointNode) insert(bounds Rect, point Point, depth int) {
+	switch {
+	case depth >= qMaxDepth:
+		// limit depth, such as for many copies of the same point
+		n.points = append(n.points, point)
+	case n.split:
+		// a point always fits in a single quad
+		q := chooseQuad(bounds, Rect{point, point})
+		if n.quads[q] == nil {
+			n.quads[q] = new(pointNode)
+		}
+		n.quads[q].insert(quadBounds(bounds, q), point, depth+1)
+	case len(n.points) >= qMaxItems:
+		points := n.points
+		n.points = nil
+		n.split = true
+		for _, p := range points {
+			n.insert(bounds, p, depth)
+		}
+		n.insert(bounds, point, depth)
+	default:
+		n.points = append(n.points, point)
+	}
+}
+
+// nearest returns the distance from the point to the nearest point in the
+// tree. Returns +Inf for an empty tree.
+func (tr *pointTree) nearest(point Point) float64 {
+	return tr.root.nearest(tr.bounds, point, math.Inf(+1))
+}
+
+func (n *pointNode) nearest(bounds Rect, point Point, best float64) float64 {
+	for _, p := range n.points {
+		best = math.Min(best, point.Distance(p))
+	}
+	if !n.split {
+		return best
+	}
+	// visit the nearest quads first, which shrinks best sooner
+	var order [4]int
+	var dists [4]float64
+	for q := 0; q < 4; q++ {
+		order[q] = q
+		dists[q] = quadBounds(bounds, q).DistanceToPoint(point)
+		for i := q; i > 0 && dists[order[i]] < dists[order[i-1]]; i-- {
+			order[i], order[i-1] = order[i-1], order[i]
+		}
+	}
+	for _, q := range order {
+		if dists[q] >= best {
+			break
+		}
+		if n.quads[q] != nil {
+			best = n.quads[q].nearest(quadBounds(bounds, q), point, best)
+		}
+	}
+	return best
+}
+
+// LargestEmptyCircle returns the largest circle with a center inside of
+// bounds that does not contain any of the points.
+//
+// The points are put into a point quadtree, which finds the nearest point
+// to each candidate center. The bounds are recursively divided into cells,
+// with the cells that may contain a better center than the best one found so
+// far being searched first. The search stops once the radius is within a
+// millionth of the size of the bounds from the true largest radius.
+//
+// Returns the center of the bounds and an infinite radius when there are no
+// points.
+func LargestEmptyCircle(points []Point, bounds Rect,
+) (center Point, radius float64) {
+	if len(points) == 0 {
+		return bounds.Center(), math.Inf(+1)
+	}
+	tr := newPointTree(points)
+	size := bounds.Min.Distance(bounds.Max)
+	precision := size * 1e-6
+
+	// cell candidates are ordered by the largest radius that may be found
+	// inside of the cell, which is the radius at the center of the cell plus
+	// the distance from its center to a corner.
+	var q queue
+	push := func(cell Rect) {
+		half := cell.Min.Distance(cell.Max) / 2
+		dist := tr.nearest(cell.Center())
+		q.push(qnode{dist: -(dist + half), a: cell.Min, b: cell.Max})
+	}
+	check := func(p Point) {
+		if dist := tr.nearest(p); dist > radius {
+			center, radius = p, dist
+		}
+	}
+	corners := []Point{bounds.Min, bounds.Max, bounds.NW(), bounds.SE()}
+	for _, corner := range corners {
+		check(corner)
+	}
+	push(bounds)
+	for {
+		node, ok := q.pop()
+		if !ok || -node.dist-radius <= precision {
+			break
+		}
+		cell := Rect{node.a, node.b}
+		check(cell.Center())
+		for i := 0; i < 4; i++ {
+			push(quadBounds(cell, i))
+		}
+	}
+	return center, radius
+}
+
+// BoundingCircle returns the smallest circle that contains all of the points
+// of the series, using Welzl's algorithm. The points are visited in a
+// shuffled order, which gives an expected linear running time. Returns the
+// zero point and a zero radius for an empty series.
+func BoundingCircle(series Series) (center Point, radius float64) {
+	points := seriesCopyPoints(series)
+	if len(points) == 0 {
+		return Point{}, 0
+	}
+	rng := rand.New(rand.NewSource(int64(len(points))))
+	rng.Shuffle(len(points), func(i, j int) {
+		points[i], points[j] = points[j], points[i]
+	})
+	inside := func(p Point) bool {
+		return center.Distance(p) <= radius*(1+1e-12)
+	}
+	center, radius = points[0], 0
+	for i := 1; i < len(points); i++ {
+		if inside(points[i]) {
+			continue
+		}
+		center, radius = points[i], 0
+		for j := 0; j < i; j++ {
+			if inside(points[j]) {
+				continue
+			}
+			center, radius = circleFrom2(points[i], points[j])
+			for k := 0; k < j; k++ {
+				if !inside(points[k]) {
+					center, radius = circleFrom3(points[i], points[j], points[k])
+				}
+			}
+		}
+	}
+	return center, radius
+}
+
+// circleFrom2 returns the smallest circle through two points.
+func circleFrom2(a, b Point) (Point, float64) {
+	center := Point{(a.X + b.X) / 2, (a.Y + b.Y) / 2}
+	return center, a.Distance(b) / 2
+}
+
+// circleFrom3 returns the circle through three points. When the points are
+// collinear, the circle of the two points that are farthest apart is
+// returned.
+func circleFrom3(a, b, c Point) (Point, float64) {
+	bx, by := b.X-a.X, b.Y-a.Y
+	cx, cy := c.X-a.X, c.Y-a.Y
+	d := 2 * (bx*cy - by*cx)
+	if d == 0 {
+		center, radius := circleFrom2(a, b)
+		if r := a.Distance(c) / 2; r > radius {
+			center, radius = circleFrom2(a, c)
+		}
+		if r := b.Distance(c) / 2; r > radius {
+			center, radius = circleFrom2(b, c)
+		}
+		return center, radius
+	}
+	b2, c2 := bx*bx+by*by, cx*cx+cy*cy
+	ux := (cy*b2 - by*c2) / d
+	uy := (bx*c2 - cx*b2) / d
+	return Point{a.X + ux, a.Y + uy}, math.Hypot(ux, uy)
+}
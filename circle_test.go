@@ -0,0 +1,109 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestPointTreeNearest(t *testing.T) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	uniform := make([]Point, 1000)
+	for i := range uniform {
+		uniform[i] = P(rng.Float64()*100, rng.Float64()*100)
+	}
+	// clustered in a corner, with copies of the same point
+	clustered := make([]Point, 1000)
+	for i := range clustered {
+		clustered[i] = P(rng.Float64(), rng.Float64())
+	}
+	for i := 0; i < 100; i++ {
+		clustered = append(clustered, P(0.5, 0.5))
+	}
+	for _, points := range [][]Point{uniform, clustered, uniform[:5]} {
+		tr := newPointTree(points)
+		for i := 0; i < 100; i++ {
+			p := P(rng.Float64()*120-10, rng.Float64()*120-10)
+			want := math.Inf(+1)
+			for _, point := range points {
+				want = math.Min(want, p.Distance(point))
+			}
+			expect(t, tr.nearest(p) == want)
+		}
+	}
+	expect(t, math.IsInf(newPointTree(nil).nearest(P(1, 1)), +1))
+}
+
+func TestLargestEmptyCircle(t *testing.T) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var points []Point
+	for i := 0; i < 200; i++ {
+		points = append(points, P(rng.Float64()*20, rng.Float64()*20))
+	}
+	center, radius := LargestEmptyCircle(points, R(0, 0, 100, 100))
+	expect(t, center.X > 90 && center.Y > 90)
+	expect(t, radius > 100)
+	for _, p := range points {
+		expect(t, center.Distance(p) >= radius)
+	}
+
+	// single point in the middle
+	center, radius = LargestEmptyCircle([]Point{{5, 5}}, R(0, 0, 10, 10))
+	expect(t, math.Abs(radius-math.Sqrt(50)) < 1e-9)
+	expect(t, center.X == 0 || center.X == 10)
+
+	// four corners, the center is the largest empty spot
+	center, radius = LargestEmptyCircle(
+		[]Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}}, R(0, 0, 10, 10))
+	expect(t, pointsNear(center, P(5, 5), 1e-4))
+	expect(t, math.Abs(radius-math.Sqrt(50)) < 1e-4)
+
+	center, radius = LargestEmptyCircle(nil, R(0, 0, 10, 10))
+	expect(t, center == P(5, 5) && math.IsInf(radius, +1))
+}
+
+func TestBoundingCircle(t *testing.T) {
+	center, radius := BoundingCircle(newRing(
+		[]Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}, nil))
+	expect(t, pointsNear(center, P(5, 5), 1e-12))
+	expect(t, math.Abs(radius-math.Sqrt(50)) < 1e-12)
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var points []Point
+	for i := 0; i < 1000; i++ {
+		points = append(points, P(rng.Float64()*20-10, rng.Float64()*40))
+	}
+	for _, series := range []Series{NewLine(points, nil), newRing(AZ, nil),
+		newRing(octagon, nil), R(0, 0, 4, 2)} {
+		center, radius := BoundingCircle(series)
+		var far int
+		for i := 0; i < series.NumPoints(); i++ {
+			dist := center.Distance(series.PointAt(i))
+			expect(t, dist <= radius+1e-9)
+			if dist >= radius-1e-9 {
+				far++
+			}
+		}
+		// the smallest circle touches at least two points
+		expect(t, far >= 2)
+		expect(t, radius <= series.Rect().Max.Distance(series.Rect().Min)/2+1e-9)
+	}
+	// a triangle where two points define the circle
+	center, radius = BoundingCircle(L(P(0, 0), P(10, 0), P(5, 1)))
+	expect(t, center == P(5, 0) && radius == 5)
+	// an acute triangle uses the circumcircle
+	center, radius = BoundingCircle(L(P(0, 0), P(10, 0), P(5, 8)))
+	expect(t, center.Distance(P(5, 8)) <= radius+1e-12 && center.Y > 0)
+	// collinear points
+	center, radius = BoundingCircle(L(P(0, 0), P(2, 2), P(5, 5), P(1, 1)))
+	expect(t, center == P(2.5, 2.5) && math.Abs(radius-math.Sqrt(12.5)) < 1e-12)
+	center, radius = BoundingCircle(L(P(3, 4)))
+	expect(t, center == P(3, 4) && radius == 0)
+	center, radius = BoundingCircle(NewLine(nil, nil))
+	expect(t, center == P(0, 0) && radius == 0)
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import "encoding/binary"
+
+// SearchCursor iterates over the segments of a series that intersect a
+// rectangle, like Search, but without a callback. The nodes of the index that
+// remain to be visited are kept on a stack that is reused by each Reset, so
+// a cursor can perform many searches without allocating.
+//
+//	cur := NewCursor(series)
+//	cur.Reset(rect)
+//	for seg, idx, ok := cur.Next(); ok; seg, idx, ok = cur.Next() {
+//		...
+//	}
+type SearchCursor struct {
+	series Series
+	base   *baseSeries // nil when the series has no usable index
+	data   []byte      // compressed index
+	rect   Rect
+	stack  []cursorFrame
+	next   int // next segment when scanning without an index
+}
+
+// cursorFrame is a node of the compressed quadtree that is being visited.
+type cursorFrame struct {
+	bounds Rect
+	addr   int    // address of the next item, flag, or quad
+	nitems uint64 // number of items that are not yet read
+	last   uint64 // last item read, for delta decoding
+	quad   int    // next quad, or -1 when the quad flag is not yet read
+}
+
+// NewCursor returns a new cursor for the series. Call Reset to start a
+// search.
+func NewCursor(series Series) *SearchCursor {
+	cur := &SearchCursor{series: series}
+	if base := seriesBase(series); base != nil && len(base.index) > 0 {
+		data := base.index
+		n := binary.LittleEndian.Uint32(data[1:])
+		cur.base, cur.data = base, data[:n:n]
+	}
+	cur.next = cur.series.NumSegments()
+	return cur
+}
+
+// Reset starts a new search for the segments that intersect the rectangle.
+func (cur *SearchCursor) Reset(rect Rect) {
+	cur.rect = rect
+	cur.stack = cur.stack[:0]
+	if cur.base == nil {
+		cur.next = 0
+		return
+	}
+	cur.push(cur.base.rect, 5)
+}
+
+func (cur *SearchCursor) push(bounds Rect, addr int) {
+	var nitems uint64
+	nitems, addr = readUvarint(cur.data, addr)
+	cur.stack = append(cur.stack, cursorFrame{
+		bounds: bounds, addr: addr, nitems: nitems, quad: -1,
+	})
+}
+
+// Next returns the next segment that intersects the rectangle, and its
+// index. Returns false when there are no more segments. Segments are
+// returned in no particular order.
+func (cur *SearchCursor) Next() (seg Segment, idx int, ok bool) {
+	if cur.base == nil {
+		n := cur.series.NumSegments()
+		for cur.next < n {
+			i := cur.next
+			cur.next++
+			seg := cur.series.SegmentAt(i)
+			if seg.Rect().IntersectsRect(cur.rect) {
+				return seg, i, true
+			}
+		}
+		return Segment{}, -1, false
+	}
+	for len(cur.stack) > 0 {
+		f := &cur.stack[len(cur.stack)-1]
+		switch {
+		case f.nitems > 0:
+			var item uint64
+			item, f.addr = readUvarint(cur.data, f.addr)
+			item += f.last
+			f.last = item
+			f.nitems--
+			seg := cur.base.SegmentAt(int(item))
+			if seg.Rect().IntersectsRect(cur.rect) {
+				return seg, int(item), true
+			}
+		case f.quad == -1:
+			if cur.data[f.addr] == 1 {
+				f.addr++
+				f.quad = 0
+			} else {
+				cur.stack = cur.stack[:len(cur.stack)-1]
+			}
+		case f.quad < 4:
+			var qsize uint64
+			qsize, f.addr = readUvarint(cur.data, f.addr)
+			q, addr := f.quad, f.addr
+			f.quad++
+			f.addr += int(qsize)
+			if qsize == 0 {
+				// empty quad
+				continue
+			}
+			qbounds := quadBounds(f.bounds, q)
+			if qbounds.IntersectsRect(cur.rect) {
+				// f is not valid after the push
+				cur.push(qbounds, addr)
+			}
+		default:
+			cur.stack = cur.stack[:len(cur.stack)-1]
+		}
+	}
+	return Segment{}, -1, false
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSearchCursor(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, series := range []Series{
+		newRing(AZ, DefaultIndexOptions),
+		newRing(AZ, NoIndexing),
+		newRing(octagon, DefaultIndexOptions),
+		NewLine(sortedInputPoints(1000), DefaultIndexOptions),
+		NewLine(AZ, DefaultIndexOptions),
+		NewLine(AZ, NoIndexing),
+		NewMutableSeries(AZ, DefaultIndexOptions),
+		R(10, 10, 20, 20),
+	} {
+		cur := NewCursor(series)
+		// the index is used by rings and lines
+		if base := seriesBase(series); base != nil && len(base.index) > 0 {
+			expect(t, cur.base == base)
+		}
+		// nothing before the first reset
+		_, _, ok := cur.Next()
+		expect(t, !ok)
+		rect := series.Rect()
+		for i := 0; i < 100; i++ {
+			x := rect.Min.X + rng.Float64()*(rect.Max.X-rect.Min.X)
+			y := rect.Min.Y + rng.Float64()*(rect.Max.Y-rect.Min.Y)
+			size := rng.Float64() * (rect.Max.X - rect.Min.X) / 4
+			query := R(x, y, x+size, y+size)
+			var a, b []int
+			series.Search(query, func(seg Segment, idx int) bool {
+				a = append(a, idx)
+				return true
+			})
+			cur.Reset(query)
+			for seg, idx, ok := cur.Next(); ok; seg, idx, ok = cur.Next() {
+				expect(t, seg == series.SegmentAt(idx))
+				b = append(b, idx)
+			}
+			_, _, ok = cur.Next()
+			expect(t, !ok)
+			sort.Ints(a)
+			sort.Ints(b)
+			expect(t, reflect.DeepEqual(a, b))
+		}
+	}
+}
+
+func TestSearchCursorReuse(t *testing.T) {
+	ring := newRing(AZ, DefaultIndexOptions)
+	cur := NewCursor(ring)
+	cur.Reset(ring.Rect())
+	var count int
+	for _, _, ok := cur.Next(); ok; _, _, ok = cur.Next() {
+		count++
+	}
+	expect(t, count == ring.NumSegments())
+	expect(t, cap(cur.stack) > 0)
+	stack := &cur.stack[:1][0]
+	// a partial search is abandoned by the reset
+	cur.Reset(ring.Rect())
+	cur.Next()
+	cur.Reset(ring.Rect())
+	expect(t, &cur.stack[:1][0] == stack)
+	allocs := testing.AllocsPerRun(100, func() {
+		cur.Reset(ring.Rect())
+		for _, _, ok := cur.Next(); ok; _, _, ok = cur.Next() {
+		}
+	})
+	expect(t, allocs == 0)
+	// an indexed line
+	line := NewLine(AZ, DefaultIndexOptions)
+	cur = NewCursor(line)
+	expect(t, cur.base == &line.baseSeries)
+	cur.Reset(line.Rect())
+	count = 0
+	for _, _, ok := cur.Next(); ok; _, _, ok = cur.Next() {
+		count++
+	}
+	expect(t, count == line.NumSegments())
+}
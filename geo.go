@@ -0,0 +1,207 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import "math"
+
+// EarthRadius is the mean radius of the earth in meters, as defined by the
+// IUGG. All geographic calculations use a spherical earth of this radius.
+const EarthRadius = 6371008.8
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
+
+// HaversineDistance returns the great-circle distance in meters between two
+// points. X is the longitude and Y is the latitude, both in degrees.
+func (point Point) HaversineDistance(other Point) float64 {
+	lat1, lat2 := radians(point.Y), radians(other.Y)
+	dlat := lat2 - lat1
+	dlon := radians(other.X - point.X)
+	sinLat, sinLon := math.Sin(dlat/2), math.Sin(dlon/2)
+	a := sinLat*sinLat + math.Cos(lat1)*math.Cos(lat2)*sinLon*sinLon
+	if a > 1 {
+		a = 1
+	}
+	return 2 * EarthRadius * math.Asin(math.Sqrt(a))
+}
+
+// geoClosestOnSegment returns the point on the segment that is closest to
+// the provided point. The calculation is performed on a local equirectangular
+// projection, which is accurate for segments that are short relative to the
+// size of the earth.
+func geoClosestOnSegment(point Point, seg Segment) Point {
+	kx := math.Cos(radians(point.Y))
+	// the segment is moved by whole turns to be near the point, so it's
+	// found across the antimeridian
+	dax := geoWrapLon(seg.A.X - point.X)
+	ax, ay := dax*kx, seg.A.Y-point.Y
+	bx, by := (dax+seg.B.X-seg.A.X)*kx, seg.B.Y-point.Y
+	dx, dy := bx-ax, by-ay
+	l2 := dx*dx + dy*dy
+	if l2 == 0 {
+		return seg.A
+	}
+	t := -(ax*dx + ay*dy) / l2
+	if t <= 0 {
+		return seg.A
+	}
+	if t >= 1 {
+		return seg.B
+	}
+	return Point{
+		X: seg.A.X + (seg.B.X-seg.A.X)*t,
+		Y: seg.A.Y + (seg.B.Y-seg.A.Y)*t,
+	}
+}
+
+// geoWrapLon returns the longitude difference in degrees wrapped to the
+// range -180 to 180.
+func geoWrapLon(dlon float64) float64 {
+	dlon = math.Mod(dlon+180, 360)
+	if dlon < 0 {
+		dlon += 360
+	}
+	return dlon - 180
+}
+
+// geoClosestInRect returns the point in the rectangle that is closest to the
+// provided point on the sphere, where the rectangle is the area between two
+// meridians and two parallels. Longitudes wrap around the antimeridian.
+func geoClosestInRect(point Point, rect Rect) Point {
+	lat := math.Max(rect.Min.Y, math.Min(rect.Max.Y, point.Y))
+	width := rect.Max.X - rect.Min.X
+	if width >= 360 || math.Mod(math.Mod(point.X-rect.Min.X, 360)+360,
+		360) <= width {
+		// Between the meridians, so the nearest point is straight north or
+		// south, along the meridian of the point.
+		return Point{point.X, lat}
+	}
+	// Otherwise the nearest point is on one of the two meridian edges. On
+	// the parallel edges, the distance only shrinks when moving toward the
+	// point, and so toward a meridian edge.
+	a := geoClosestOnMeridian(point, rect.Min.X, rect.Min.Y, rect.Max.Y)
+	b := geoClosestOnMeridian(point, rect.Max.X, rect.Min.Y, rect.Max.Y)
+	if point.HaversineDistance(b) < point.HaversineDistance(a) {
+		return b
+	}
+	return a
+}
+
+// geoClosestOnMeridian returns the point on the meridian at lon, between
+// minLat and maxLat, that is closest to the provided point on the sphere.
+func geoClosestOnMeridian(point Point, lon, minLat, maxLat float64) Point {
+	dlon := radians(geoWrapLon(point.X - lon))
+	if math.Abs(dlon) > math.Pi/2 {
+		// The nearest point of the whole great circle is on the other side
+		// of the poles, so the distance has no minimum between minLat and
+		// maxLat, and the nearest point is at one of the ends.
+		a, b := Point{lon, minLat}, Point{lon, maxLat}
+		if point.HaversineDistance(b) < point.HaversineDistance(a) {
+			return b
+		}
+		return a
+	}
+	// The foot of the perpendicular from the point to the great circle of
+	// the meridian. The distance grows moving away from the foot, so the
+	// nearest point is the foot clamped to the latitudes.
+	foot := degrees(math.Atan2(math.Tan(radians(point.Y)), math.Cos(dlon)))
+	return Point{lon, math.Max(minLat, math.Min(maxLat, foot))}
+}
+
+// HaversineMetric is a DistanceMetric for the great-circle distance in
+// meters from a point. X is the longitude and Y is the latitude, both in
+// degrees.
+type HaversineMetric Point
+
+// RectDist returns the distance to the nearest point in the rectangle, which
+// is the area between two meridians and two parallels.
+func (m HaversineMetric) RectDist(rect Rect) float64 {
+	return Point(m).HaversineDistance(geoClosestInRect(Point(m), rect))
+}
+
+// SegDist returns the distance to the nearest point on the segment.
+func (m HaversineMetric) SegDist(seg Segment) float64 {
+	return Point(m).HaversineDistance(geoClosestOnSegment(Point(m), seg))
+}
+
+// DistanceToSeriesGeo returns the segment in the series that is nearest to
+// point, along with its index and the distance in meters.
+// Points are treated as longitude (X) and latitude (Y) in degrees.
+// Returns NaN if the series is empty.
+func DistanceToSeriesGeo(series Series, point Point) (Segment, int, float64) {
+	return DistanceToSeriesMetric(series, HaversineMetric(point))
+}
+
+type geoVec struct{ x, y, z float64 }
+
+func geoVecFromPoint(point Point) geoVec {
+	lon, lat := radians(point.X), radians(point.Y)
+	sinLat, cosLat := math.Sincos(lat)
+	sinLon, cosLon := math.Sincos(lon)
+	return geoVec{cosLat * cosLon, cosLat * sinLon, sinLat}
+}
+
+func (v geoVec) point() Point {
+	return Point{
+		X: degrees(math.Atan2(v.y, v.x)),
+		Y: degrees(math.Atan2(v.z, math.Hypot(v.x, v.y))),
+	}
+}
+
+func (v geoVec) cross(w geoVec) geoVec {
+	return geoVec{v.y*w.z - v.z*w.y, v.z*w.x - v.x*w.z, v.x*w.y - v.y*w.x}
+}
+
+func (v geoVec) dot(w geoVec) float64 {
+	return v.x*w.x + v.y*w.y + v.z*w.z
+}
+
+func (v geoVec) length() float64 {
+	return math.Sqrt(v.dot(v))
+}
+
+func (v geoVec) scale(s float64) geoVec {
+	return geoVec{v.x * s, v.y * s, v.z * s}
+}
+
+// geoOnArc returns true if the unit vector p, which must be on the great
+// circle with the normal n, lies within the minor arc from a to b.
+func geoOnArc(a, b, n, p geoVec) bool {
+	const eps = 1e-12
+	return a.cross(p).dot(n) >= -eps && p.cross(b).dot(n) >= -eps
+}
+
+// GreatCircleIntersection returns the point where two geographic segments
+// cross. Each segment is treated as the shortest great-circle arc between its
+// points, with X as the longitude and Y as the latitude, both in degrees.
+// Arcs that span the antimeridian are supported. Returns false if the arcs do
+// not cross, or if either arc is degenerate or both lie on the same great
+// circle.
+func (seg Segment) GreatCircleIntersection(other Segment) (Point, bool) {
+	const eps = 1e-15
+	a, b := geoVecFromPoint(seg.A), geoVecFromPoint(seg.B)
+	c, d := geoVecFromPoint(other.A), geoVecFromPoint(other.B)
+	n1, n2 := a.cross(b), c.cross(d)
+	if n1.length() < eps || n2.length() < eps {
+		return Point{}, false
+	}
+	l := n1.cross(n2)
+	llen := l.length()
+	if llen < eps {
+		return Point{}, false
+	}
+	l = l.scale(1 / llen)
+	for _, p := range [2]geoVec{l, l.scale(-1)} {
+		if geoOnArc(a, b, n1, p) && geoOnArc(c, d, n2, p) {
+			return p.point(), true
+		}
+	}
+	return Point{}, false
+}
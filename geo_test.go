@@ -0,0 +1,157 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var (
+	london     = P(-0.1278, 51.5074)
+	paris      = P(2.3522, 48.8566)
+	newYork    = P(-74.0060, 40.7128)
+	losAngeles = P(-118.2437, 34.0522)
+)
+
+// geoMetersEq returns true if two distances are within one kilometer.
+func geoMetersEq(a, b float64) bool {
+	return math.Abs(a-b) < 1000
+}
+
+func TestPointHaversineDistance(t *testing.T) {
+	expect(t, london.HaversineDistance(london) == 0)
+	expect(t, geoMetersEq(london.HaversineDistance(paris), 343_556))
+	expect(t, geoMetersEq(paris.HaversineDistance(london), 343_556))
+	expect(t, geoMetersEq(newYork.HaversineDistance(losAngeles), 3_935_751))
+	expect(t, geoMetersEq(P(0, 0).HaversineDistance(P(180, 0)),
+		math.Pi*EarthRadius))
+	expect(t, geoMetersEq(P(0, 90).HaversineDistance(P(0, -90)),
+		math.Pi*EarthRadius))
+}
+
+func TestDistanceToSeriesGeo(t *testing.T) {
+	p := P(-111.1, 33.3)
+	var expSeg Segment
+	var expDist float64
+	series := makeSeries(AZ, true, true, NoIndexing)
+	for i := 0; i < series.NumSegments(); i++ {
+		seg := series.SegmentAt(i)
+		dist := p.HaversineDistance(geoClosestOnSegment(p, seg))
+		if i == 0 || dist < expDist {
+			expSeg, expDist = seg, dist
+		}
+	}
+	dualPolyTest(t, AZ, nil, func(t *testing.T, poly *Poly) {
+		seg, _, dist := DistanceToSeriesGeo(poly.Exterior, p)
+		expect(t, seg == expSeg)
+		expect(t, dist == expDist)
+		// roughly 1.87 degrees of longitude at 33.3° latitude
+		expect(t, dist > 150_000 && dist < 200_000)
+
+		// a point on a vertex
+		_, _, dist = DistanceToSeriesGeo(poly.Exterior, AZ[10])
+		expect(t, dist == 0)
+	})
+	_, _, dist := DistanceToSeriesGeo(&baseSeries{}, p)
+	expect(t, math.IsNaN(dist))
+}
+
+func TestSegmentGreatCircleIntersection(t *testing.T) {
+	near := func(a, b Point) bool { return pointsNear(a, b, 1e-9) }
+	p, ok := S(-10, 0, 10, 0).GreatCircleIntersection(S(0, -10, 0, 10))
+	expect(t, ok && near(p, P(0, 0)))
+	p, ok = S(-10, -10, 10, 10).GreatCircleIntersection(S(-10, 10, 10, -10))
+	expect(t, ok && near(p, P(0, 0)))
+	p, ok = S(-10, 1, 10, 1).GreatCircleIntersection(S(5, -10, 5, 10))
+	expect(t, ok && p.X > 4.99 && p.X < 5.01 && p.Y > 1)
+	_, ok = S(-10, 0, 10, 0).GreatCircleIntersection(S(20, -10, 20, 10))
+	expect(t, !ok)
+	_, ok = S(-10, 0, 10, 0).GreatCircleIntersection(S(0, 1, 0, 10))
+	expect(t, !ok)
+
+	// antimeridian
+	p, ok = S(170, 0, -170, 0).GreatCircleIntersection(S(180, -10, 180, 10))
+	expect(t, ok && near(P(math.Abs(p.X), p.Y), P(180, 0)))
+	p, ok = S(175, -5, -175, 5).GreatCircleIntersection(S(-175, -5, 175, 5))
+	expect(t, ok && near(P(math.Abs(p.X), p.Y), P(180, 0)))
+
+	// both arcs pass over the north pole
+	p, ok = S(0, 80, 180, 80).GreatCircleIntersection(S(90, 80, -90, 80))
+	expect(t, ok && math.Abs(p.Y-90) < 1e-9)
+	// near pole, but one arc stays on the far side
+	_, ok = S(0, 80, 180, 80).GreatCircleIntersection(S(90, 80, 100, 85))
+	expect(t, !ok)
+
+	// same great circle and degenerate arcs
+	_, ok = S(-10, 0, 10, 0).GreatCircleIntersection(S(0, 0, 20, 0))
+	expect(t, !ok)
+	_, ok = S(5, 5, 5, 5).GreatCircleIntersection(S(0, 0, 20, 20))
+	expect(t, !ok)
+}
+
+func TestHaversineMetricRectDist(t *testing.T) {
+	// across the antimeridian
+	m := HaversineMetric(P(-179, 0))
+	dist := m.RectDist(R(170, -10, 179.9, 10))
+	expect(t, geoMetersEq(dist, P(-179, 0).HaversineDistance(P(179.9, 0))))
+	expect(t, dist < 130_000)
+	// inside, and straight north or south
+	expect(t, m.RectDist(R(-180, -10, -170, 10)) == 0)
+	expect(t, m.RectDist(R(181, -10, 182, 10)) == 0)
+	expect(t, geoMetersEq(m.RectDist(R(-179.5, 5, -178, 10)),
+		P(-179, 0).HaversineDistance(P(-179, 5))))
+	// the great circle bends toward the pole, so the nearest point is north
+	// of the point
+	m = HaversineMetric(P(0, 60))
+	dist = m.RectDist(R(40, 0, 50, 70))
+	expect(t, dist < P(0, 60).HaversineDistance(P(40, 60))-100_000)
+
+	// never more than the distance to any point in the rectangle
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		point := P(rng.Float64()*360-180, rng.Float64()*170-85)
+		x, y := rng.Float64()*360-180, rng.Float64()*170-85
+		rect := R(x, y, x+rng.Float64()*60, math.Min(y+rng.Float64()*60, 90))
+		dist := HaversineMetric(point).RectDist(rect)
+		min := math.Inf(1)
+		for j := 0; j <= 50; j++ {
+			for k := 0; k <= 50; k++ {
+				p := P(rect.Min.X+(rect.Max.X-rect.Min.X)*float64(j)/50,
+					rect.Min.Y+(rect.Max.Y-rect.Min.Y)*float64(k)/50)
+				min = math.Min(min, point.HaversineDistance(p))
+			}
+		}
+		expect(t, dist <= min+1e-6)
+		expect(t, dist > min-50_000)
+	}
+}
+
+func TestDistanceToSeriesGeoAntimeridian(t *testing.T) {
+	// a ring between longitudes 170 and 179.9
+	var points []Point
+	for lat := -30.0; lat < 30; lat++ {
+		points = append(points, P(179.9, lat))
+	}
+	for lat := 30.0; lat > -30; lat-- {
+		points = append(points, P(170, lat))
+	}
+	for _, opts := range []*IndexOptions{DefaultIndexOptions, NoIndexing} {
+		ring := newRing(points, opts)
+		expect(t, (len(ring.Index()) > 0) == (opts == DefaultIndexOptions))
+		for _, p := range []Point{P(-179, 0), P(-179.5, 12.5), P(-170, -20)} {
+			m := HaversineMetric(p)
+			expDist := math.Inf(1)
+			for i := 0; i < ring.NumSegments(); i++ {
+				expDist = math.Min(expDist, m.SegDist(ring.SegmentAt(i)))
+			}
+			seg, _, dist := DistanceToSeriesGeo(ring, p)
+			expect(t, dist == expDist)
+			expect(t, seg.A.X == 179.9 && seg.B.X == 179.9)
+			expect(t, geoMetersEq(dist, p.HaversineDistance(P(179.9, p.Y))))
+		}
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// AppendGeoJSON appends the GeoJSON geometry object of the geometry to dst.
+// A Point is a "Point", a Line is a "LineString", and a Poly or Rect is a
+// "Polygon". MultiLine and MultiPoly are "MultiLineString" and
+// "MultiPolygon". Polygon rings are closed and follow the right-hand rule,
+// with the exterior counter-clockwise and the holes clockwise. Nil and empty
+// geometries have empty coordinates, and unknown geometries are null.
+func AppendGeoJSON(dst []byte, g Geometry) []byte {
+	switch g := g.(type) {
+	case Point:
+		dst = append(dst, `{"type":"Point","coordinates":`...)
+		dst = appendGeoJSONPoint(dst, g)
+	case Rect:
+		dst = append(dst, `{"type":"Polygon","coordinates":[`...)
+		dst = appendGeoJSONRing(dst, g, false)
+		dst = append(dst, ']')
+	case *Line:
+		dst = append(dst, `{"type":"LineString","coordinates":`...)
+		dst = appendGeoJSONLine(dst, g)
+	case *Poly:
+		dst = append(dst, `{"type":"Polygon","coordinates":`...)
+		dst = appendGeoJSONPoly(dst, g)
+	case *MultiLine:
+		dst = append(dst, `{"type":"MultiLineString","coordinates":[`...)
+		if g != nil {
+			for i, line := range g.Lines {
+				if i > 0 {
+					dst = append(dst, ',')
+				}
+				dst = appendGeoJSONLine(dst, line)
+			}
+		}
+		dst = append(dst, ']')
+	case *MultiPoly:
+		dst = append(dst, `{"type":"MultiPolygon","coordinates":[`...)
+		if g != nil {
+			for i, poly := range g.Polys {
+				if i > 0 {
+					dst = append(dst, ',')
+				}
+				dst = appendGeoJSONPoly(dst, poly)
+			}
+		}
+		dst = append(dst, ']')
+	default:
+		return append(dst, "null"...)
+	}
+	return append(dst, '}')
+}
+
+func appendGeoJSONPoint(dst []byte, point Point) []byte {
+	dst = append(dst, '[')
+	dst = strconv.AppendFloat(dst, point.X, 'f', -1, 64)
+	dst = append(dst, ',')
+	dst = strconv.AppendFloat(dst, point.Y, 'f', -1, 64)
+	return append(dst, ']')
+}
+
+func appendGeoJSONLine(dst []byte, line *Line) []byte {
+	dst = append(dst, '[')
+	if line != nil {
+		n := line.NumPoints()
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendGeoJSONPoint(dst, line.PointAt(i))
+		}
+	}
+	return append(dst, ']')
+}
+
+func appendGeoJSONPoly(dst []byte, poly *Poly) []byte {
+	dst = append(dst, '[')
+	if !poly.Empty() {
+		dst = appendGeoJSONRing(dst, poly.Exterior, false)
+		for _, hole := range poly.Holes {
+			dst = append(dst, ',')
+			dst = appendGeoJSONRing(dst, hole, true)
+		}
+	}
+	return append(dst, ']')
+}
+
+// appendGeoJSONRing appends the closed ring in clockwise or
+// counter-clockwise order.
+func appendGeoJSONRing(dst []byte, ring Ring, clockwise bool) []byte {
+	dst = append(dst, '[')
+	n := ring.NumPoints()
+	if n > 0 {
+		closed := ring.PointAt(0) == ring.PointAt(n-1)
+		reverse := ring.Clockwise() != clockwise
+		for i := 0; i < n; i++ {
+			j := i
+			if reverse {
+				j = n - 1 - i
+			}
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendGeoJSONPoint(dst, ring.PointAt(j))
+		}
+		if !closed {
+			dst = append(dst, ',')
+			j := 0
+			if reverse {
+				j = n - 1
+			}
+			dst = appendGeoJSONPoint(dst, ring.PointAt(j))
+		}
+	}
+	return append(dst, ']')
+}
+
+// ErrInvalidGeoJSON is returned when parsing malformed GeoJSON data.
+var ErrInvalidGeoJSON = errors.New("invalid geojson")
+
+// ParseGeoJSON parses a GeoJSON geometry object. The "Point", "LineString",
+// "Polygon", "MultiLineString", and "MultiPolygon" types are supported, and a
+// "Feature" is parsed as its geometry. Positions must have two or three
+// values, where the third value is ignored. Polygon rings that are not
+// closed are closed automatically. The series are built using
+// DefaultIndexOptions.
+func ParseGeoJSON(data []byte) (Geometry, error) {
+	var obj struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+		Geometry    json.RawMessage `json:"geometry"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGeoJSON, err)
+	}
+	switch obj.Type {
+	case "Feature":
+		if len(obj.Geometry) == 0 || string(obj.Geometry) == "null" {
+			return nil, fmt.Errorf("%w: feature has no geometry",
+				ErrInvalidGeoJSON)
+		}
+		return ParseGeoJSON(obj.Geometry)
+	case "GeometryCollection":
+		return nil, fmt.Errorf("%w: GeometryCollection is not supported",
+			ErrInvalidGeoJSON)
+	case "Point":
+		var coords []float64
+		if err := parseGeoJSONCoords(obj.Coordinates, &coords); err != nil {
+			return nil, err
+		}
+		return parseGeoJSONPoint(coords)
+	case "LineString":
+		var coords [][]float64
+		if err := parseGeoJSONCoords(obj.Coordinates, &coords); err != nil {
+			return nil, err
+		}
+		return parseGeoJSONLine(coords)
+	case "Polygon":
+		var coords [][][]float64
+		if err := parseGeoJSONCoords(obj.Coordinates, &coords); err != nil {
+			return nil, err
+		}
+		return parseGeoJSONPoly(coords)
+	case "MultiLineString":
+		var coords [][][]float64
+		if err := parseGeoJSONCoords(obj.Coordinates, &coords); err != nil {
+			return nil, err
+		}
+		lines := make([]*Line, len(coords))
+		for i, coords := range coords {
+			line, err := parseGeoJSONLine(coords)
+			if err != nil {
+				return nil, err
+			}
+			lines[i] = line
+		}
+		return NewMultiLine(lines), nil
+	case "MultiPolygon":
+		var coords [][][][]float64
+		if err := parseGeoJSONCoords(obj.Coordinates, &coords); err != nil {
+			return nil, err
+		}
+		polys := make([]*Poly, len(coords))
+		for i, coords := range coords {
+			poly, err := parseGeoJSONPoly(coords)
+			if err != nil {
+				return nil, err
+			}
+			polys[i] = poly
+		}
+		return NewMultiPoly(polys), nil
+	}
+	return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidGeoJSON, obj.Type)
+}
+
+func parseGeoJSONCoords(data json.RawMessage, coords interface{}) error {
+	if len(data) == 0 {
+		return fmt.Errorf("%w: missing coordinates", ErrInvalidGeoJSON)
+	}
+	if err := json.Unmarshal(data, coords); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidGeoJSON, err)
+	}
+	return nil
+}
+
+func parseGeoJSONPoint(coords []float64) (Point, error) {
+	if len(coords) < 2 || len(coords) > 3 {
+		return Point{}, fmt.Errorf("%w: position has %d values",
+			ErrInvalidGeoJSON, len(coords))
+	}
+	return Point{coords[0], coords[1]}, nil
+}
+
+func parseGeoJSONPoints(coords [][]float64) ([]Point, error) {
+	points := make([]Point, len(coords))
+	for i, coords := range coords {
+		point, err := parseGeoJSONPoint(coords)
+		if err != nil {
+			return nil, err
+		}
+		points[i] = point
+	}
+	return points, nil
+}
+
+func parseGeoJSONLine(coords [][]float64) (*Line, error) {
+	points, err := parseGeoJSONPoints(coords)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) == 1 {
+		return nil, fmt.Errorf("%w: line has one position", ErrInvalidGeoJSON)
+	}
+	return NewLine(points, DefaultIndexOptions), nil
+}
+
+func parseGeoJSONPoly(coords [][][]float64) (*Poly, error) {
+	if len(coords) == 0 {
+		return new(Poly), nil
+	}
+	rings := make([][]Point, len(coords))
+	for i, coords := range coords {
+		points, err := parseGeoJSONPoints(coords)
+		if err != nil {
+			return nil, err
+		}
+		if len(points) > 0 && points[0] != points[len(points)-1] {
+			points = append(points, points[0])
+		}
+		if len(points) < 4 {
+			return nil, fmt.Errorf("%w: ring has fewer than 3 positions",
+				ErrInvalidGeoJSON)
+		}
+		rings[i] = points
+	}
+	return NewPoly(rings[0], rings[1:], DefaultIndexOptions), nil
+}
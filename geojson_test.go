@@ -0,0 +1,118 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAppendGeoJSON(t *testing.T) {
+	check := func(g Geometry, expected string) {
+		t.Helper()
+		expect(t, string(AppendGeoJSON(nil, g)) == expected)
+	}
+	check(P(1, 2.5), `{"type":"Point","coordinates":[1,2.5]}`)
+	check(P(-0.000001, 1e10),
+		`{"type":"Point","coordinates":[-0.000001,10000000000]}`)
+	check(L(P(0, 0), P(1, 1), P(2, 0)),
+		`{"type":"LineString","coordinates":[[0,0],[1,1],[2,0]]}`)
+	check(R(0, 0, 2, 1),
+		`{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,1],[0,1],[0,0]]]}`)
+	// clockwise exterior and counter-clockwise hole, without closing points
+	check(NewPoly(
+		[]Point{{0, 0}, {0, 10}, {10, 10}, {10, 0}},
+		[][]Point{{{2, 2}, {4, 2}, {4, 4}, {2, 4}}}, nil),
+		`{"type":"Polygon","coordinates":[`+
+			`[[10,0],[10,10],[0,10],[0,0],[10,0]],`+
+			`[[2,4],[4,4],[4,2],[2,2],[2,4]]]}`)
+	// already following the right-hand rule
+	check(NewPoly(
+		[]Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		[][]Point{{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}}}, nil),
+		`{"type":"Polygon","coordinates":[`+
+			`[[0,0],[10,0],[10,10],[0,10],[0,0]],`+
+			`[[2,2],[2,4],[4,4],[4,2],[2,2]]]}`)
+	check(NewMultiLine([]*Line{L(P(0, 0), P(1, 1)), L(P(2, 2), P(3, 3))}),
+		`{"type":"MultiLineString","coordinates":`+
+			`[[[0,0],[1,1]],[[2,2],[3,3]]]}`)
+	check(NewMultiPoly([]*Poly{{Exterior: R(0, 0, 1, 1)}}),
+		`{"type":"MultiPolygon","coordinates":`+
+			`[[[[0,0],[1,0],[1,1],[0,1],[0,0]]]]}`)
+	// nil and empty
+	var line *Line
+	var poly *Poly
+	var mline *MultiLine
+	var mpoly *MultiPoly
+	check(line, `{"type":"LineString","coordinates":[]}`)
+	check(NewLine(nil, nil), `{"type":"LineString","coordinates":[]}`)
+	check(poly, `{"type":"Polygon","coordinates":[]}`)
+	check(new(Poly), `{"type":"Polygon","coordinates":[]}`)
+	check(mline, `{"type":"MultiLineString","coordinates":[]}`)
+	check(mpoly, `{"type":"MultiPolygon","coordinates":[]}`)
+	check(nil, `null`)
+	// appends
+	expect(t, string(AppendGeoJSON([]byte("x="), P(1, 2))) ==
+		`x={"type":"Point","coordinates":[1,2]}`)
+}
+
+func TestParseGeoJSON(t *testing.T) {
+	// round trips
+	for _, g := range []Geometry{
+		P(1, 2.5),
+		L(P(0, 0), P(1, 1), P(2, 0)),
+		NewPoly(
+			[]Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+			[][]Point{{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}}}, nil),
+		NewPoly(AZ, nil, nil),
+		NewMultiLine([]*Line{L(P(0, 0), P(1, 1)), L(P(2, 2), P(3, 3))}),
+		NewMultiPoly([]*Poly{{Exterior: R(0, 0, 1, 1)}}),
+	} {
+		data := AppendGeoJSON(nil, g)
+		g2, err := ParseGeoJSON(data)
+		expect(t, err == nil)
+		expect(t, string(AppendGeoJSON(nil, g2)) == string(data))
+	}
+	// a polygon with a hole, not closed, and with altitudes
+	g, err := ParseGeoJSON([]byte(`{"type":"Polygon","coordinates":[
+		[[0,0,5],[10,0,5],[10,10,5],[0,10,5]],
+		[[2,2],[2,4],[4,4],[4,2],[2,2]]]}`))
+	expect(t, err == nil)
+	poly := g.(*Poly)
+	expect(t, len(poly.Holes) == 1)
+	expect(t, poly.Exterior.NumPoints() == 5)
+	expect(t, poly.Exterior.PointAt(4) == P(0, 0))
+	expect(t, poly.Area() == 96)
+	expect(t, len(poly.Exterior.Index()) > 0 ||
+		poly.Exterior.NumPoints() < DefaultIndexOptions.MinPoints)
+	// feature
+	g, err = ParseGeoJSON([]byte(`{"type":"Feature","properties":{},
+		"geometry":{"type":"Point","coordinates":[1,2]}}`))
+	expect(t, err == nil && g.(Point) == P(1, 2))
+	// empty
+	g, err = ParseGeoJSON([]byte(`{"type":"LineString","coordinates":[]}`))
+	expect(t, err == nil && g.Empty())
+	g, err = ParseGeoJSON([]byte(`{"type":"Polygon","coordinates":[]}`))
+	expect(t, err == nil && g.Empty())
+	// errors
+	for _, data := range []string{
+		``,
+		`[]`,
+		`{"type":"Point"}`,
+		`{"type":"Point","coordinates":[1]}`,
+		`{"type":"Point","coordinates":[1,2,3,4]}`,
+		`{"type":"Point","coordinates":"1,2"}`,
+		`{"type":"LineString","coordinates":[[1,2],[3]]}`,
+		`{"type":"LineString","coordinates":[[1,2]]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[1,1],[0,0]]]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1]],[[0,0]]]}`,
+		`{"type":"Feature","geometry":null}`,
+		`{"type":"GeometryCollection","geometries":[]}`,
+		`{"type":"Circle","coordinates":[1,2]}`,
+	} {
+		_, err := ParseGeoJSON([]byte(data))
+		expect(t, errors.Is(err, ErrInvalidGeoJSON))
+	}
+}
@@ -19,4 +19,6 @@ type Geometry interface {
 }
 
 // require conformance
-var _ = []Geometry{Point{}, Rect{}, &Line{}, &Poly{}}
+var _ = []Geometry{
+	Point{}, Rect{}, &Line{}, &Poly{}, &MultiLine{}, &MultiPoly{},
+}
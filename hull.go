@@ -0,0 +1,121 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import "sort"
+
+// ConvexHull returns the convex hull of the points. The hull is a closed ring
+// in counter-clockwise order, where the last point is the same as the first.
+// Points that are collinear with a hull edge are not included.
+// Returns nil when there are no points.
+func ConvexHull(points []Point) []Point {
+	hull, _ := ConvexHullEx(points)
+	return hull
+}
+
+// ConvexHullEx returns the convex hull of the points, see ConvexHull, and
+// whether the points were already convex. The points are convex when the set
+// of distinct input points is the same as the set of hull points.
+func ConvexHullEx(points []Point) (hull []Point, wasConvex bool) {
+	if len(points) == 0 {
+		return nil, false
+	}
+	sorted := make([]Point, len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].X != sorted[j].X {
+			return sorted[i].X < sorted[j].X
+		}
+		return sorted[i].Y < sorted[j].Y
+	})
+	// remove duplicates
+	unique := sorted[:1]
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i] != unique[len(unique)-1] {
+			unique = append(unique, sorted[i])
+		}
+	}
+	if len(unique) < 3 {
+		hull = append(hull, unique...)
+		return append(hull, unique[0]), true
+	}
+	cross := func(o, a, b Point) float64 {
+		return (a.X-o.X)*(b.Y-o.Y) - (a.Y-o.Y)*(b.X-o.X)
+	}
+	// turn is positive when the last two hull points and p turn left
+	turn := func(p Point) float64 {
+		return cross(hull[len(hull)-2], hull[len(hull)-1], p)
+	}
+	// Andrew's monotone chain
+	hull = make([]Point, 0, len(unique)*2)
+	for _, p := range unique {
+		for len(hull) >= 2 && turn(p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for i := len(unique) - 2; i >= 0; i-- {
+		p := unique[i]
+		for len(hull) >= lower && turn(p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	// the last point is the same as the first, closing the ring.
+	return hull, len(hull)-1 == len(unique)
+}
+
+// convexRingPoints returns the distinct points of a convex ring in
+// counter-clockwise order, starting with the lowest, then leftmost, point.
+func convexRingPoints(ring Ring) []Point {
+	n := ring.NumSegments()
+	points := make([]Point, 0, n)
+	for i := 0; i < n; i++ {
+		points = append(points, ring.SegmentAt(i).A)
+	}
+	if ring.Clockwise() {
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+	}
+	var start int
+	for i, p := range points {
+		if p.Y < points[start].Y ||
+			(p.Y == points[start].Y && p.X < points[start].X) {
+			start = i
+		}
+	}
+	return append(points[start:], points[:start]...)
+}
+
+// MinkowskiSum returns the Minkowski sum of the exteriors of two convex
+// polygons, which is computed in O(n+m) time by merging the edges of both
+// polygons in angular order. The result is not valid for concave polygons,
+// and holes are ignored. Returns nil if either polygon is empty.
+func MinkowskiSum(a, b *Poly) *Poly {
+	if a.Empty() || b.Empty() {
+		return nil
+	}
+	p, q := convexRingPoints(a.Exterior), convexRingPoints(b.Exterior)
+	n, m := len(p), len(q)
+	p = append(p, p[0], p[1])
+	q = append(q, q[0], q[1])
+	points := make([]Point, 0, n+m+1)
+	var i, j int
+	for i < n || j < m {
+		points = append(points, Point{p[i].X + q[j].X, p[i].Y + q[j].Y})
+		cross := (p[i+1].X-p[i].X)*(q[j+1].Y-q[j].Y) -
+			(p[i+1].Y-p[i].Y)*(q[j+1].X-q[j].X)
+		if cross >= 0 && i < n {
+			i++
+		}
+		if cross <= 0 && j < m {
+			j++
+		}
+	}
+	points = append(points, points[0])
+	return NewPoly(points, nil, DefaultIndexOptions)
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"testing"
+)
+
+func TestConvexHull(t *testing.T) {
+	hull := ConvexHull(concave1)
+	expect(t, len(hull) == 6)
+	expect(t, hull[0] == hull[len(hull)-1])
+	ring := newRing(hull, DefaultIndexOptions)
+	expect(t, ring.Convex())
+	expect(t, !ring.Clockwise())
+	expect(t, ring.Rect() == R(0, 0, 10, 10))
+	for _, p := range concave1 {
+		expect(t, ringContainsPoint(ring, p, true).hit)
+	}
+	hull = ConvexHull(AZ)
+	ring = newRing(hull, DefaultIndexOptions)
+	expect(t, ring.Convex())
+	expect(t, ring.Rect() == newRing(AZ, DefaultIndexOptions).Rect())
+	for _, p := range AZ {
+		expect(t, ringContainsPoint(ring, p, true).hit)
+	}
+	expect(t, ConvexHull(nil) == nil)
+	expect(t, len(ConvexHull([]Point{{1, 1}})) == 2)
+	expect(t, len(ConvexHull([]Point{{1, 1}, {2, 2}, {1, 1}})) == 3)
+	// collinear points
+	hull = ConvexHull([]Point{{0, 0}, {5, 0}, {10, 0}, {10, 10}, {0, 10}})
+	expect(t, len(hull) == 5)
+}
+
+func TestConvexHullEx(t *testing.T) {
+	hull, wasConvex := ConvexHullEx(octagon)
+	expect(t, wasConvex)
+	expect(t, len(hull) == len(octagon))
+	set := make(map[Point]bool)
+	for _, p := range octagon {
+		set[p] = true
+	}
+	for _, p := range hull {
+		expect(t, set[p])
+	}
+	_, wasConvex = ConvexHullEx(octagon[:len(octagon)-1])
+	expect(t, wasConvex)
+	hull, wasConvex = ConvexHullEx(concave1)
+	expect(t, !wasConvex)
+	expect(t, len(hull) == 6)
+	_, wasConvex = ConvexHullEx(bowtie)
+	expect(t, !wasConvex)
+}
+
+func TestMinkowskiSum(t *testing.T) {
+	a := NewPoly([]Point{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}, nil, nil)
+	b := NewPoly([]Point{{10, 10}, {10, 13}, {13, 13}, {13, 10}}, nil, nil)
+	sum := MinkowskiSum(a, b)
+	expect(t, sum.Rect() == R(10, 10, 15, 15))
+	expect(t, sum.Area() == 25)
+	expect(t, sum.Exterior.Convex())
+	expect(t, !sum.Exterior.Clockwise())
+
+	// square and triangle
+	c := NewPoly([]Point{{0, 0}, {2, 0}, {1, 2}, {0, 0}}, nil, nil)
+	sum = MinkowskiSum(a, c)
+	expect(t, sum.Rect() == R(0, 0, 4, 4))
+	expect(t, sum.Exterior.Convex())
+	// area(A+B) = area(A) + area(B) + 2*mixed area
+	expect(t, sum.Area() == 4+2+8)
+
+	sum = MinkowskiSum(newPolySimple(octagon, nil), &Poly{Exterior: R(0, 0, 1, 1)})
+	expect(t, sum.Rect() == R(0, 0, 11, 11))
+	expect(t, sum.Exterior.NumSegments() == 8)
+
+	expect(t, MinkowskiSum(a, nil) == nil)
+	expect(t, MinkowskiSum(&Poly{}, a) == nil)
+}
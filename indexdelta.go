@@ -0,0 +1,130 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// The index delta format is:
+//
+//	size     uvarint  number of bytes in the new index
+//	ops      a sequence of:
+//	         0, offset uvarint, length uvarint  copy bytes from the old index
+//	         1, length uvarint, [length]byte    insert new bytes
+const (
+	indexDeltaCopy   = 0
+	indexDeltaInsert = 1
+)
+
+// indexDeltaBlock is the smallest run of bytes that is copied from the old
+// index. Shorter runs are inserted, because a copy takes about as many bytes
+// as the run itself.
+const indexDeltaBlock = 8
+
+// ErrInvalidIndexDelta is returned when applying a malformed index delta, or
+// a delta that was made from a different old index.
+var ErrInvalidIndexDelta = errors.New("invalid index delta")
+
+// IndexDelta returns a delta that turns the old index into the new index,
+// where both are compressed indexes, such as those returned by
+// Series.Index. When the series has small changes, most of its index is
+// unchanged, so the delta is usually much smaller than the new index. Use
+// ApplyIndexDelta to recreate the new index from the old index.
+func IndexDelta(oldIndex, newIndex []byte) []byte {
+	// the first offset of each block in the old index
+	blocks := make(map[uint64]int)
+	for i := 0; i+indexDeltaBlock <= len(oldIndex); i++ {
+		key := binary.LittleEndian.Uint64(oldIndex[i:])
+		if _, ok := blocks[key]; !ok {
+			blocks[key] = i
+		}
+	}
+	var buf [binary.MaxVarintLen64]byte
+	appendUvarint := func(dst []byte, n int) []byte {
+		return append(dst, buf[:binary.PutUvarint(buf[:], uint64(n))]...)
+	}
+	delta := appendUvarint(nil, len(newIndex))
+	var start int // start of the bytes that are not yet in the delta
+	flush := func(end int) {
+		if end > start {
+			delta = append(delta, indexDeltaInsert)
+			delta = appendUvarint(delta, end-start)
+			delta = append(delta, newIndex[start:end]...)
+		}
+	}
+	i := 0
+	for i+indexDeltaBlock <= len(newIndex) {
+		offset, ok := blocks[binary.LittleEndian.Uint64(newIndex[i:])]
+		if !ok {
+			i++
+			continue
+		}
+		n := indexDeltaBlock
+		for i+n < len(newIndex) && offset+n < len(oldIndex) &&
+			newIndex[i+n] == oldIndex[offset+n] {
+			n++
+		}
+		flush(i)
+		delta = append(delta, indexDeltaCopy)
+		delta = appendUvarint(delta, offset)
+		delta = appendUvarint(delta, n)
+		i += n
+		start = i
+	}
+	flush(len(newIndex))
+	return delta
+}
+
+// ApplyIndexDelta returns the new index that the delta was made from using
+// IndexDelta. The old index must be the same as the one that the delta was
+// made from. Returns ErrInvalidIndexDelta if the delta is malformed or does
+// not fit the old index.
+func ApplyIndexDelta(oldIndex, delta []byte) ([]byte, error) {
+	readUvarint := func() (uint64, bool) {
+		n, size := binary.Uvarint(delta)
+		if size <= 0 {
+			return 0, false
+		}
+		delta = delta[size:]
+		return n, true
+	}
+	size, ok := readUvarint()
+	if !ok {
+		return nil, ErrInvalidIndexDelta
+	}
+	var newIndex []byte
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		switch op {
+		case indexDeltaCopy:
+			offset, ok1 := readUvarint()
+			n, ok2 := readUvarint()
+			if !ok1 || !ok2 || offset > uint64(len(oldIndex)) ||
+				n > uint64(len(oldIndex))-offset {
+				return nil, ErrInvalidIndexDelta
+			}
+			newIndex = append(newIndex, oldIndex[offset:offset+n]...)
+		case indexDeltaInsert:
+			n, ok := readUvarint()
+			if !ok || n > uint64(len(delta)) {
+				return nil, ErrInvalidIndexDelta
+			}
+			newIndex = append(newIndex, delta[:n]...)
+			delta = delta[n:]
+		default:
+			return nil, ErrInvalidIndexDelta
+		}
+		if uint64(len(newIndex)) > size {
+			return nil, ErrInvalidIndexDelta
+		}
+	}
+	if uint64(len(newIndex)) != size {
+		return nil, ErrInvalidIndexDelta
+	}
+	return newIndex, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestIndexDelta(t *testing.T) {
+	oldRing := newRing(AZ, DefaultIndexOptions)
+	// move one point and add another
+	points := seriesCopyPoints(oldRing)
+	points[10] = points[10].Move(0.01, -0.02)
+	points = append(points[:50:50], append([]Point{
+		points[49].Lerp(points[50], 0.5),
+	}, points[50:]...)...)
+	updated := newRing(points, DefaultIndexOptions)
+	oldIndex, newIndex := oldRing.Index(), updated.Index()
+	expect(t, len(oldIndex) > 0 && !bytes.Equal(oldIndex, newIndex))
+	delta := IndexDelta(oldIndex, newIndex)
+	expect(t, len(delta) < len(newIndex))
+	index, err := ApplyIndexDelta(oldIndex, delta)
+	expect(t, err == nil && bytes.Equal(index, newIndex))
+
+	// the search results of the rebuilt index match
+	rebuilt := *updated.(*baseSeries)
+	rebuilt.index = index
+	rect := updated.Rect()
+	for _, query := range []Rect{
+		rect, R(rect.Min.X, rect.Min.Y, rect.Center().X, rect.Center().Y),
+		R(-112, 33, -111, 34), R(0, 0, 1, 1),
+	} {
+		var a, b []int
+		updated.Search(query, func(seg Segment, idx int) bool {
+			a = append(a, idx)
+			return true
+		})
+		rebuilt.Search(query, func(seg Segment, idx int) bool {
+			b = append(b, idx)
+			return true
+		})
+		sort.Ints(a)
+		sort.Ints(b)
+		expect(t, reflect.DeepEqual(a, b))
+	}
+
+	// unrelated, identical, and empty indexes
+	other := newRing(octagon, DefaultIndexOptions).Index()
+	for _, pair := range [][2][]byte{
+		{oldIndex, oldIndex}, {oldIndex, other}, {nil, newIndex},
+		{newIndex, nil}, {nil, nil},
+	} {
+		index, err := ApplyIndexDelta(pair[0], IndexDelta(pair[0], pair[1]))
+		expect(t, err == nil && bytes.Equal(index, pair[1]))
+	}
+	expect(t, len(IndexDelta(oldIndex, oldIndex)) < 10)
+}
+
+func TestIndexDeltaInvalid(t *testing.T) {
+	oldIndex := newRing(AZ, DefaultIndexOptions).Index()
+	newIndex := newRing(AZ[1:], DefaultIndexOptions).Index()
+	delta := IndexDelta(oldIndex, newIndex)
+	for _, bad := range [][]byte{
+		nil,
+		delta[:len(delta)-1],
+		append(append([]byte{}, delta...), indexDeltaInsert, 1, 0),
+		{3, indexDeltaCopy, 0, 4},
+		{1, indexDeltaInsert, 2, 0},
+		{1, 7, 1, 0},
+		{0xff},
+	} {
+		_, err := ApplyIndexDelta(oldIndex, bad)
+		expect(t, errors.Is(err, ErrInvalidIndexDelta))
+	}
+	// a copy past the end of the old index
+	_, err := ApplyIndexDelta(oldIndex[:10], delta)
+	expect(t, errors.Is(err, ErrInvalidIndexDelta))
+}
@@ -4,6 +4,11 @@
 
 package geometry
 
+import (
+	"math"
+	"sort"
+)
+
 // Line is a open series of points
 type Line struct {
 	baseSeries
@@ -25,6 +30,124 @@ func (line *Line) Move(deltaX, deltaY float64) *Line {
 	return nline
 }
 
+// Length returns the total length of the line.
+func (line *Line) Length() float64 {
+	if line == nil {
+		return 0
+	}
+	return seriesLength(line)
+}
+
+// Interpolate returns the point that is the fraction of the length along the
+// line. A fraction of zero or less returns the first point, and one or more
+// returns the last point.
+func (line *Line) Interpolate(fraction float64) Point {
+	if line == nil || line.NumPoints() == 0 {
+		return Point{}
+	}
+	if fraction <= 0 {
+		return line.PointAt(0)
+	}
+	if fraction >= 1 {
+		return line.PointAt(line.NumPoints() - 1)
+	}
+	return seriesPointAtLength(line, line.Length()*fraction)
+}
+
+// InterpolateAttr returns the value at the distance along the line, where
+// values holds one value per point. The value is linearly interpolated
+// between the values of the points on either side of the distance. The
+// distance is clamped to the length of the line. Returns false if the line is
+// empty or the number of values does not match the number of points.
+func (line *Line) InterpolateAttr(values []float64, dist float64,
+) (float64, bool) {
+	if line == nil || line.Empty() || len(values) != line.NumPoints() {
+		return 0, false
+	}
+	if dist <= 0 {
+		return values[0], true
+	}
+	n := line.NumSegments()
+	for i := 0; i < n; i++ {
+		seg := line.SegmentAt(i)
+		segLen := seg.A.Distance(seg.B)
+		if dist < segLen {
+			t := dist / segLen
+			return values[i] + (values[i+1]-values[i])*t, true
+		}
+		dist -= segLen
+	}
+	return values[len(values)-1], true
+}
+
+// Sinuosity returns the ratio of the length of the line to the straight-line
+// distance between its first and last points. A straight line is 1, and a
+// meandering line is greater than 1. Returns +Inf when the first and last
+// points are the same, unless the line also has no length, in which case 1
+// is returned.
+func (line *Line) Sinuosity() float64 {
+	length := line.Length()
+	if length == 0 {
+		return 1
+	}
+	dist := line.PointAt(0).Distance(line.PointAt(line.NumPoints() - 1))
+	if dist == 0 {
+		return math.Inf(+1)
+	}
+	return length / dist
+}
+
+// SelfIntersections returns the points where the line crosses or touches
+// itself. Segments that are next to each other share an endpoint, which is
+// not an intersection, and this includes the first and last segments when
+// the line ends at its first point. The index is used to find the candidate
+// segments. Each point is only returned once, and the points are ordered by
+// the indexes of the segments that intersect.
+func (line *Line) SelfIntersections() []Point {
+	if line == nil {
+		return nil
+	}
+	n := line.NumSegments()
+	if n < 3 {
+		return nil
+	}
+	loops := line.PointAt(0) == line.PointAt(line.NumPoints()-1)
+	type crossing struct {
+		point Point
+		i, j  int
+	}
+	var crossings []crossing
+	var points []Point
+	for i := 0; i < n; i++ {
+		seg := line.SegmentAt(i)
+		line.Search(seg.Rect(), func(other Segment, j int) bool {
+			if j <= i+1 || (loops && i == 0 && j == n-1) {
+				return true
+			}
+			points = appendIntersectionPoints(points[:0], seg, other)
+			for _, point := range points {
+				crossings = append(crossings, crossing{point, i, j})
+			}
+			return true
+		})
+	}
+	sort.Slice(crossings, func(a, b int) bool {
+		if crossings[a].i != crossings[b].i {
+			return crossings[a].i < crossings[b].i
+		}
+		return crossings[a].j < crossings[b].j
+	})
+	var result []Point
+	seen := make(map[Point]bool, len(crossings))
+	for _, crossing := range crossings {
+		if !seen[crossing.point] {
+			seen[crossing.point] = true
+			result = append(result, crossing.point)
+		}
+	}
+	return result
+}
+
 func (line *Line) ContainsPoint(point Point) bool {
 	if line == nil {
 		return false
@@ -151,3 +274,24 @@ func (line *Line) ContainsPoly(poly *Poly) bool {
 func (line *Line) IntersectsPoly(poly *Poly) bool {
 	return poly.IntersectsLine(line)
 }
+
+// OffsetProfile samples line a at evenly spaced positions along its length
+// and returns the signed distance from each sample to the nearest segment of
+// line b. Distances are positive when the sample is to the left of b.
+func OffsetProfile(a, b *Line, samples int) []float64 {
+	if a == nil || b == nil || a.Empty() || b.Empty() || samples <= 0 {
+		return nil
+	}
+	profile := make([]float64, samples)
+	length := seriesLength(a)
+	for i := 0; i < samples; i++ {
+		var dist float64
+		if samples > 1 {
+			dist = length * float64(i) / float64(samples-1)
+		}
+		point := seriesPointAtLength(a, dist)
+		seg, _, _ := DistanceToSeriesMetric(b, EuclideanMetric(point))
+		profile[i] = seg.SignedDistance(point)
+	}
+	return profile
+}
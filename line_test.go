@@ -5,6 +5,9 @@
 package geometry
 
 import (
+	"math"
+	"math/rand"
+	"reflect"
 	"testing"
 )
 
@@ -182,3 +185,125 @@ func TestNumSegments(t *testing.T) {
 	expect(t, ln.NumPoints() == 3)
 	expect(t, ln.NumSegments() == 2)
 }
+
+func TestOffsetProfile(t *testing.T) {
+	a := L(P(0, 0), P(4, 0), P(10, 0), P(20, 0))
+	b := L(P(0, 5), P(20, 5))
+	profile := OffsetProfile(a, b, 11)
+	expect(t, len(profile) == 11)
+	for _, dist := range profile {
+		expect(t, math.Abs(dist+5) < 1e-9)
+	}
+	profile = OffsetProfile(b, a, 7)
+	expect(t, len(profile) == 7)
+	for _, dist := range profile {
+		expect(t, math.Abs(dist-5) < 1e-9)
+	}
+	// diverging lines
+	c := L(P(0, 0), P(20, 10))
+	profile = OffsetProfile(c, a, 3)
+	expect(t, len(profile) == 3)
+	expect(t, profile[0] == 0 && profile[1] == 5 && profile[2] == 10)
+	expect(t, len(OffsetProfile(a, b, 1)) == 1)
+	expect(t, OffsetProfile(a, b, 0) == nil)
+	expect(t, OffsetProfile(a, nil, 10) == nil)
+}
+
+func TestLineLength(t *testing.T) {
+	expect(t, L(P(0, 0), P(3, 4), P(3, 10)).Length() == 11)
+	expect(t, L(P(0, 0)).Length() == 0)
+	var line *Line
+	expect(t, line.Length() == 0)
+}
+
+func TestLineSinuosity(t *testing.T) {
+	expect(t, L(P(0, 0), P(10, 0)).Sinuosity() == 1)
+	expect(t, L(P(0, 0), P(5, 0), P(10, 0)).Sinuosity() == 1)
+	// zig-zag, each leg is 5 long and spans 4 along the x axis
+	zigzag := L(P(0, 0), P(4, 3), P(8, 0), P(12, 3), P(16, 0))
+	expect(t, zigzag.Sinuosity() == 20.0/16.0)
+	expect(t, L(P(0, 0), P(10, 0), P(0, 0)).Sinuosity() == math.Inf(+1))
+	expect(t, L(P(5, 5), P(5, 5)).Sinuosity() == 1)
+	expect(t, L().Sinuosity() == 1)
+}
+
+func TestLineInterpolate(t *testing.T) {
+	// the midpoint by length is on the last segment, the midpoint by vertex
+	// count is (2, 0).
+	line := L(P(0, 0), P(1, 0), P(2, 0), P(3, 0), P(3, 10))
+	expect(t, line.Interpolate(0.5) == P(3, 3.5))
+	expect(t, line.Interpolate(0) == P(0, 0))
+	expect(t, line.Interpolate(-1) == P(0, 0))
+	expect(t, line.Interpolate(1) == P(3, 10))
+	expect(t, line.Interpolate(2) == P(3, 10))
+	expect(t, line.Interpolate(0.1) == P(1.3, 0))
+	expect(t, L(P(5, 5)).Interpolate(0.5) == P(5, 5))
+	var nilLine *Line
+	expect(t, nilLine.Interpolate(0.5) == P(0, 0))
+	expect(t, L().Interpolate(0.5) == P(0, 0))
+}
+
+func TestLineInterpolateAttr(t *testing.T) {
+	line := L(P(0, 0), P(10, 0), P(10, 5), P(10, 5), P(20, 5))
+	values := []float64{100, 200, 150, 150, 50}
+	attr := func(dist float64) float64 {
+		value, ok := line.InterpolateAttr(values, dist)
+		expect(t, ok)
+		return value
+	}
+	// at the vertices
+	expect(t, attr(0) == 100)
+	expect(t, attr(10) == 200)
+	expect(t, attr(15) == 150)
+	expect(t, attr(25) == 50)
+	// mid-segment
+	expect(t, attr(5) == 150)
+	expect(t, attr(12.5) == 175)
+	expect(t, attr(20) == 100)
+	// clamped
+	expect(t, attr(-5) == 100)
+	expect(t, attr(100) == 50)
+
+	_, ok := line.InterpolateAttr(values[:4], 5)
+	expect(t, !ok)
+	_, ok = L(P(0, 0)).InterpolateAttr([]float64{1}, 0)
+	expect(t, !ok)
+	var nilLine *Line
+	_, ok = nilLine.InterpolateAttr(nil, 0)
+	expect(t, !ok)
+}
+
+func TestLineSelfIntersections(t *testing.T) {
+	// figure eight
+	eight := L(P(0, 0), P(2, 2), P(2, 0), P(0, 2), P(0, 0))
+	points := eight.SelfIntersections()
+	expect(t, len(points) == 1 && points[0] == P(1, 1))
+	// an open figure eight
+	eight = L(P(0, 0), P(2, 2), P(2, 0), P(0, 2))
+	points = eight.SelfIntersections()
+	expect(t, len(points) == 1 && points[0] == P(1, 1))
+	// simple lines
+	zigzag := L(P(0, 0), P(1, 1), P(2, 0), P(3, 1))
+	expect(t, len(zigzag.SelfIntersections()) == 0)
+	expect(t, len(NewLine(octagon, nil).SelfIntersections()) == 0)
+	expect(t, len(NewLine(AZ, DefaultIndexOptions).SelfIntersections()) == 0)
+	expect(t, len(L(P(0, 0), P(1, 1)).SelfIntersections()) == 0)
+	expect(t, (*Line)(nil).SelfIntersections() == nil)
+	// touching an earlier vertex
+	touch := L(P(0, 0), P(4, 0), P(4, 4), P(2, 0), P(2, -2))
+	points = touch.SelfIntersections()
+	expect(t, len(points) == 1 && points[0] == P(2, 0))
+	// crossing at a vertex is only reported once
+	cross := L(P(0, 0), P(2, 0), P(4, 0), P(4, 2), P(2, 2), P(2, -2))
+	points = cross.SelfIntersections()
+	expect(t, len(points) == 1 && points[0] == P(2, 0))
+	// indexed matches brute force
+	rng := rand.New(rand.NewSource(1))
+	var walk []Point
+	for i := 0; i < 500; i++ {
+		walk = append(walk, P(rng.Float64()*100, rng.Float64()*100))
+	}
+	a := NewLine(walk, DefaultIndexOptions).SelfIntersections()
+	b := NewLine(walk, NoIndexing).SelfIntersections()
+	expect(t, len(a) > 0 && reflect.DeepEqual(a, b))
+}
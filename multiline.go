@@ -0,0 +1,223 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import "math"
+
+// MultiLine is a collection of lines, such as the roads of a network.
+//
+// A MultiLine created with NewMultiLine keeps the bounding rectangle of each
+// line, which is used to skip lines that cannot match before performing the
+// more expensive line operations. The rectangles are only used while the
+// Lines slice holds the same lines that it was created with. Assigning new
+// lines to Lines falls back to the rectangles of the lines themselves.
+type MultiLine struct {
+	Lines []*Line
+	lines []*Line // lines of the rects
+	rects []Rect  // rectangle of each line
+	rect  Rect    // union of all rects
+}
+
+// NewMultiLine returns a new MultiLine from the lines.
+func NewMultiLine(lines []*Line) *MultiLine {
+	mline := &MultiLine{Lines: lines}
+	mline.lines = make([]*Line, len(lines))
+	copy(mline.lines, lines)
+	mline.rects = make([]Rect, len(lines))
+	var init bool
+	for i, line := range lines {
+		if line == nil || line.Empty() {
+			continue
+		}
+		mline.rects[i] = line.Rect()
+		if !init {
+			mline.rect = mline.rects[i]
+			init = true
+		} else {
+			mline.rect = mline.rect.Union(mline.rects[i])
+		}
+	}
+	return mline
+}
+
+// indexed returns true if the member rectangles are those of the lines that
+// are in Lines.
+func (mline *MultiLine) indexed() bool {
+	if mline.lines == nil || len(mline.lines) != len(mline.Lines) {
+		return false
+	}
+	for i, line := range mline.Lines {
+		if line != mline.lines[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// lineRect returns the rectangle of the line at index, using the member
+// rectangles when indexed is true.
+func (mline *MultiLine) lineRect(index int, indexed bool) Rect {
+	if indexed {
+		return mline.rects[index]
+	}
+	return mline.Lines[index].Rect()
+}
+
+// search calls iter for each non-empty line with a rectangle that
+// intersects the provided rectangle.
+func (mline *MultiLine) search(rect Rect, iter func(line *Line) bool) bool {
+	if mline == nil {
+		return false
+	}
+	indexed := mline.indexed()
+	if indexed && !mline.rect.IntersectsRect(rect) {
+		return false
+	}
+	for i, line := range mline.Lines {
+		if line == nil || line.Empty() {
+			continue
+		}
+		if mline.lineRect(i, indexed).IntersectsRect(rect) && iter(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// Empty returns true if all of the lines are empty.
+func (mline *MultiLine) Empty() bool {
+	if mline == nil {
+		return true
+	}
+	for _, line := range mline.Lines {
+		if line != nil && !line.Empty() {
+			return false
+		}
+	}
+	return true
+}
+
+// Rect returns the union of the rectangles of all non-empty lines.
+func (mline *MultiLine) Rect() Rect {
+	if mline == nil {
+		return Rect{}
+	}
+	if mline.indexed() {
+		return mline.rect
+	}
+	var rect Rect
+	var init bool
+	for _, line := range mline.Lines {
+		if line == nil || line.Empty() {
+			continue
+		}
+		if !init {
+			rect = line.Rect()
+			init = true
+		} else {
+			rect = rect.Union(line.Rect())
+		}
+	}
+	return rect
+}
+
+// NearestPoint returns the point on any of the lines that is closest to the
+// provided point, along with the index of that line. Lines are visited using
+// their segment index, and lines with a rectangle that is farther away than
+// the nearest point found so far are skipped.
+// Returns -1 for the index if all of the lines are empty.
+func (mline *MultiLine) NearestPoint(point Point) (Point, int) {
+	nearest, lineIndex := point, -1
+	if mline == nil {
+		return nearest, lineIndex
+	}
+	best := math.Inf(+1)
+	indexed := mline.indexed()
+	for i, line := range mline.Lines {
+		if line == nil || line.Empty() ||
+			mline.lineRect(i, indexed).DistanceToPoint(point) >= best {
+			continue
+		}
+		seg, _, dist := DistanceToSeriesMetric(&line.baseSeries,
+			EuclideanMetric(point))
+		if dist < best {
+			best = dist
+			nearest = seg.ClosestPoint(point)
+			lineIndex = i
+		}
+	}
+	return nearest, lineIndex
+}
+
+// ContainsPoint returns true if any of the lines contain the point.
+func (mline *MultiLine) ContainsPoint(point Point) bool {
+	return mline.search(point.Rect(), func(line *Line) bool {
+		return line.ContainsPoint(point)
+	})
+}
+
+// IntersectsPoint returns true if any of the lines intersect the point.
+func (mline *MultiLine) IntersectsPoint(point Point) bool {
+	return mline.search(point.Rect(), func(line *Line) bool {
+		return line.IntersectsPoint(point)
+	})
+}
+
+// ContainsRect returns true if any one of the lines contains the entire
+// rectangle.
+func (mline *MultiLine) ContainsRect(rect Rect) bool {
+	return mline.search(rect, func(line *Line) bool {
+		return line.ContainsRect(rect)
+	})
+}
+
+// IntersectsRect returns true if any of the lines intersect the rectangle.
+func (mline *MultiLine) IntersectsRect(rect Rect) bool {
+	return mline.search(rect, func(line *Line) bool {
+		return line.IntersectsRect(rect)
+	})
+}
+
+// ContainsLine returns true if any one of the lines contains the entire
+// other line.
+func (mline *MultiLine) ContainsLine(other *Line) bool {
+	if other == nil {
+		return false
+	}
+	return mline.search(other.Rect(), func(line *Line) bool {
+		return line.ContainsLine(other)
+	})
+}
+
+// IntersectsLine returns true if any of the lines intersect the other line.
+func (mline *MultiLine) IntersectsLine(other *Line) bool {
+	if other == nil {
+		return false
+	}
+	return mline.search(other.Rect(), func(line *Line) bool {
+		return line.IntersectsLine(other)
+	})
+}
+
+// ContainsPoly returns true if any one of the lines contains the entire
+// polygon.
+func (mline *MultiLine) ContainsPoly(poly *Poly) bool {
+	if poly == nil {
+		return false
+	}
+	return mline.search(poly.Rect(), func(line *Line) bool {
+		return line.ContainsPoly(poly)
+	})
+}
+
+// IntersectsPoly returns true if any of the lines intersect the polygon.
+func (mline *MultiLine) IntersectsPoly(poly *Poly) bool {
+	if poly == nil {
+		return false
+	}
+	return mline.search(poly.Rect(), func(line *Line) bool {
+		return line.IntersectsPoly(poly)
+	})
+}
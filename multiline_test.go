@@ -0,0 +1,97 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math"
+	"testing"
+)
+
+func testMultiLines() []*MultiLine {
+	lines := []*Line{
+		NewLine([]Point{{0, 0}, {10, 0}, {10, 10}}, nil),
+		nil,
+		NewLine([]Point{{20, 0}, {20, 10}, {30, 10}}, nil),
+		NewLine(AZ, nil),
+	}
+	return []*MultiLine{NewMultiLine(lines), {Lines: lines}}
+}
+
+func TestMultiLineRect(t *testing.T) {
+	for _, mline := range testMultiLines() {
+		expect(t, !mline.Empty())
+		expect(t, mline.Rect() == R(-114.816591, 0, 30, 37.00426))
+	}
+	var mline *MultiLine
+	expect(t, mline.Empty() && mline.Rect() == Rect{})
+	expect(t, NewMultiLine([]*Line{nil, NewLine(nil, nil)}).Empty())
+}
+
+func TestMultiLineNearestPoint(t *testing.T) {
+	for _, mline := range testMultiLines() {
+		point, idx := mline.NearestPoint(P(5, 2))
+		expect(t, idx == 0 && point == P(5, 0))
+		point, idx = mline.NearestPoint(P(14, 5))
+		expect(t, idx == 0 && point == P(10, 5))
+		point, idx = mline.NearestPoint(P(16, 5))
+		expect(t, idx == 2 && point == P(20, 5))
+		point, idx = mline.NearestPoint(P(25, 20))
+		expect(t, idx == 2 && point == P(25, 10))
+		point, idx = mline.NearestPoint(P(-110, 20))
+		expect(t, idx == 3)
+		// compare with a brute force search of every segment
+		var expDist = math.Inf(+1)
+		for i := 0; i < len(AZ)-1; i++ {
+			expDist = math.Min(expDist, S(AZ[i].X, AZ[i].Y,
+				AZ[i+1].X, AZ[i+1].Y).Distance(P(-110, 20)))
+		}
+		expect(t, point.Distance(P(-110, 20)) == expDist)
+	}
+	var mline *MultiLine
+	_, idx := mline.NearestPoint(P(0, 0))
+	expect(t, idx == -1)
+	_, idx = NewMultiLine([]*Line{nil}).NearestPoint(P(0, 0))
+	expect(t, idx == -1)
+}
+
+func TestMultiLineIntersects(t *testing.T) {
+	for _, mline := range testMultiLines() {
+		expect(t, mline.IntersectsPoint(P(10, 5)))
+		expect(t, mline.ContainsPoint(P(25, 10)))
+		expect(t, !mline.IntersectsPoint(P(15, 5)))
+		expect(t, mline.IntersectsLine(L(P(5, -5), P(5, 5))))
+		expect(t, mline.IntersectsLine(L(P(15, 5), P(25, 5))))
+		expect(t, !mline.IntersectsLine(L(P(12, 0), P(18, 10))))
+		expect(t, mline.ContainsLine(L(P(2, 0), P(8, 0))))
+		expect(t, !mline.ContainsLine(L(P(2, 0), P(28, 0))))
+		expect(t, mline.IntersectsRect(R(9, 4, 11, 6)))
+		expect(t, !mline.IntersectsRect(R(12, 4, 18, 6)))
+		expect(t, mline.IntersectsPoly(&Poly{Exterior: R(19, 4, 21, 6)}))
+		expect(t, !mline.IntersectsPoly(&Poly{Exterior: R(12, 4, 18, 6)}))
+		expect(t, !mline.IntersectsLine(nil) && !mline.ContainsPoly(nil))
+	}
+}
+
+func TestMultiLineReplaced(t *testing.T) {
+	lines := []*Line{
+		NewLine([]Point{{0, 0}, {10, 0}}, nil),
+		NewLine([]Point{{0, 5}, {10, 5}}, nil),
+	}
+	mline := NewMultiLine(append([]*Line(nil), lines...))
+	expect(t, mline.indexed())
+	// replacing a line is seen by the queries
+	mline.Lines[0] = NewLine([]Point{{20, 20}, {30, 20}}, nil)
+	expect(t, !mline.indexed())
+	expect(t, mline.IntersectsPoint(P(25, 20)))
+	expect(t, !mline.IntersectsPoint(P(5, 0)))
+	expect(t, mline.Rect() == R(0, 5, 30, 20))
+	p, idx := mline.NearestPoint(P(25, 21))
+	expect(t, p == P(25, 20) && idx == 0)
+	// so is appending a line
+	mline = NewMultiLine(lines[:1:1])
+	mline.Lines = append(mline.Lines, lines[1])
+	expect(t, mline.IntersectsPoint(P(5, 5)))
+	expect(t, mline.Rect() == R(0, 0, 10, 5))
+}
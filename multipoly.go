@@ -0,0 +1,180 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+// MultiPoly is a collection of polygons, such as a chain of islands.
+//
+// A MultiPoly created with NewMultiPoly keeps an R-tree of the polygons,
+// which is used to skip polygons that cannot match before performing the
+// more expensive polygon operations. The R-tree is only used while the Polys
+// slice holds the same polygons that it was created with. Assigning new
+// polygons to Polys falls back to checking each polygon.
+type MultiPoly struct {
+	Polys []*Poly
+	polys []*Poly   // polygons in the index
+	index PolyIndex // R-tree of the non-empty polygons
+}
+
+// NewMultiPoly returns a new MultiPoly from the polygons.
+func NewMultiPoly(polys []*Poly) *MultiPoly {
+	mpoly := &MultiPoly{Polys: polys}
+	mpoly.polys = make([]*Poly, len(polys))
+	copy(mpoly.polys, polys)
+	for _, poly := range polys {
+		mpoly.index.Insert(poly)
+	}
+	return mpoly
+}
+
+// indexed returns true if the index holds the polygons that are in Polys.
+func (mpoly *MultiPoly) indexed() bool {
+	if mpoly.polys == nil || len(mpoly.polys) != len(mpoly.Polys) {
+		return false
+	}
+	for i, poly := range mpoly.Polys {
+		if poly != mpoly.polys[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// search calls iter for each non-empty polygon with a rectangle that
+// intersects the provided rectangle. Returns true if iter returned true.
+func (mpoly *MultiPoly) search(rect Rect, iter func(poly *Poly) bool) bool {
+	if mpoly == nil {
+		return false
+	}
+	if mpoly.indexed() {
+		var found bool
+		mpoly.index.Search(rect, func(poly *Poly) bool {
+			found = iter(poly)
+			return !found
+		})
+		return found
+	}
+	for _, poly := range mpoly.Polys {
+		if poly.Empty() {
+			continue
+		}
+		if poly.Rect().IntersectsRect(rect) && iter(poly) {
+			return true
+		}
+	}
+	return false
+}
+
+// Empty returns true if all of the polygons are empty.
+func (mpoly *MultiPoly) Empty() bool {
+	if mpoly == nil {
+		return true
+	}
+	for _, poly := range mpoly.Polys {
+		if !poly.Empty() {
+			return false
+		}
+	}
+	return true
+}
+
+// Rect returns the union of the rectangles of all non-empty polygons.
+func (mpoly *MultiPoly) Rect() Rect {
+	if mpoly == nil {
+		return Rect{}
+	}
+	if mpoly.indexed() {
+		if mpoly.index.root == nil {
+			return Rect{}
+		}
+		return mpoly.index.root.rect()
+	}
+	var rect Rect
+	var init bool
+	for _, poly := range mpoly.Polys {
+		if poly.Empty() {
+			continue
+		}
+		if !init {
+			rect = poly.Rect()
+			init = true
+		} else {
+			rect = rect.Union(poly.Rect())
+		}
+	}
+	return rect
+}
+
+// ContainsPoint returns true if any of the polygons contain the point.
+func (mpoly *MultiPoly) ContainsPoint(point Point) bool {
+	return mpoly.search(point.Rect(), func(poly *Poly) bool {
+		return poly.ContainsPoint(point)
+	})
+}
+
+// IntersectsPoint returns true if any of the polygons intersect the point.
+func (mpoly *MultiPoly) IntersectsPoint(point Point) bool {
+	return mpoly.search(point.Rect(), func(poly *Poly) bool {
+		return poly.IntersectsPoint(point)
+	})
+}
+
+// ContainsRect returns true if any one of the polygons contains the entire
+// rectangle. A rectangle that is only contained by multiple polygons
+// together, such as two adjacent polygons, is not contained.
+func (mpoly *MultiPoly) ContainsRect(rect Rect) bool {
+	return mpoly.search(rect, func(poly *Poly) bool {
+		return poly.ContainsRect(rect)
+	})
+}
+
+// IntersectsRect returns true if any of the polygons intersect the rectangle.
+func (mpoly *MultiPoly) IntersectsRect(rect Rect) bool {
+	return mpoly.search(rect, func(poly *Poly) bool {
+		return poly.IntersectsRect(rect)
+	})
+}
+
+// ContainsLine returns true if any one of the polygons contains the entire
+// line.
+func (mpoly *MultiPoly) ContainsLine(line *Line) bool {
+	if line == nil {
+		return false
+	}
+	return mpoly.search(line.Rect(), func(poly *Poly) bool {
+		return poly.ContainsLine(line)
+	})
+}
+
+// IntersectsLine returns true if any of the polygons intersect the line.
+func (mpoly *MultiPoly) IntersectsLine(line *Line) bool {
+	if line == nil {
+		return false
+	}
+	return mpoly.search(line.Rect(), func(poly *Poly) bool {
+		return poly.IntersectsLine(line)
+	})
+}
+
+// ContainsPoly returns true if any one of the polygons contains the entire
+// other polygon.
+func (mpoly *MultiPoly) ContainsPoly(other *Poly) bool {
+	if other == nil {
+		return false
+	}
+	return mpoly.search(other.Rect(), func(poly *Poly) bool {
+		return poly.ContainsPoly(other)
+	})
+}
+
+// IntersectsPoly returns true if any of the polygons intersect the other
+// polygon.
+func (mpoly *MultiPoly) IntersectsPoly(other *Poly) bool {
+	if other == nil {
+		return false
+	}
+	return mpoly.search(other.Rect(), func(poly *Poly) bool {
+		return poly.IntersectsPoly(other)
+	})
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import "testing"
+
+func testMultiPolys() []*MultiPoly {
+	left := NewPoly([]Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}, nil, nil)
+	right := NewPoly([]Point{{20, 0}, {30, 0}, {30, 10}, {20, 10}, {20, 0}},
+		[][]Point{{{24, 4}, {26, 4}, {26, 6}, {24, 6}, {24, 4}}}, nil)
+	return []*MultiPoly{
+		NewMultiPoly([]*Poly{left, right}),
+		{Polys: []*Poly{left, right}},
+	}
+}
+
+func TestMultiPolyRect(t *testing.T) {
+	for _, mpoly := range testMultiPolys() {
+		expect(t, mpoly.Rect() == R(0, 0, 30, 10))
+		expect(t, !mpoly.Empty())
+	}
+	var mpoly *MultiPoly
+	expect(t, mpoly.Empty())
+	expect(t, mpoly.Rect() == Rect{})
+	mpoly = NewMultiPoly([]*Poly{nil, NewPoly(nil, nil, nil),
+		NewPoly([]Point{{5, 5}, {6, 5}, {6, 6}, {5, 5}}, nil, nil)})
+	expect(t, !mpoly.Empty())
+	expect(t, mpoly.Rect() == R(5, 5, 6, 6))
+	expect(t, NewMultiPoly(nil).Empty())
+}
+
+func TestMultiPolyContainsPoint(t *testing.T) {
+	for _, mpoly := range testMultiPolys() {
+		expect(t, mpoly.ContainsPoint(P(5, 5)))
+		expect(t, mpoly.ContainsPoint(P(0, 0)))
+		expect(t, mpoly.ContainsPoint(P(22, 8)))
+		expect(t, mpoly.IntersectsPoint(P(22, 8)))
+		// between the squares
+		expect(t, !mpoly.ContainsPoint(P(15, 5)))
+		// in the hole
+		expect(t, !mpoly.ContainsPoint(P(25, 5)))
+		expect(t, !mpoly.IntersectsPoint(P(25, 5)))
+		// outside of everything
+		expect(t, !mpoly.ContainsPoint(P(-5, 5)))
+		expect(t, !mpoly.ContainsPoint(P(5, 15)))
+	}
+}
+
+func TestMultiPolyRectLinePoly(t *testing.T) {
+	for _, mpoly := range testMultiPolys() {
+		expect(t, mpoly.ContainsRect(R(1, 1, 9, 9)))
+		expect(t, mpoly.ContainsRect(R(21, 1, 23, 9)))
+		expect(t, !mpoly.ContainsRect(R(5, 5, 25, 6)))
+		expect(t, mpoly.IntersectsRect(R(5, 5, 25, 6)))
+		expect(t, !mpoly.IntersectsRect(R(12, 2, 18, 8)))
+		expect(t, !mpoly.IntersectsRect(R(24.5, 4.5, 25.5, 5.5)))
+
+		expect(t, mpoly.ContainsLine(L(P(1, 1), P(9, 9))))
+		expect(t, !mpoly.ContainsLine(L(P(5, 5), P(25, 5))))
+		expect(t, mpoly.IntersectsLine(L(P(15, 5), P(25, 8))))
+		expect(t, !mpoly.IntersectsLine(L(P(12, 0), P(18, 10))))
+		expect(t, !mpoly.ContainsLine(nil) && !mpoly.IntersectsLine(nil))
+
+		inner := NewPoly([]Point{{21, 1}, {23, 1}, {23, 3}, {21, 1}}, nil, nil)
+		between := NewPoly([]Point{{12, 1}, {18, 1}, {18, 3}, {12, 1}}, nil, nil)
+		span := NewPoly([]Point{{5, 1}, {25, 1}, {25, 3}, {5, 1}}, nil, nil)
+		expect(t, mpoly.ContainsPoly(inner))
+		expect(t, mpoly.IntersectsPoly(inner))
+		expect(t, !mpoly.ContainsPoly(between))
+		expect(t, !mpoly.IntersectsPoly(between))
+		expect(t, !mpoly.ContainsPoly(span))
+		expect(t, mpoly.IntersectsPoly(span))
+		expect(t, !mpoly.ContainsPoly(nil) && !mpoly.IntersectsPoly(nil))
+	}
+}
+
+func TestMultiPolyIndex(t *testing.T) {
+	var polys []*Poly
+	for i := 0; i < 100; i++ {
+		x := float64(i%10) * 10
+		y := float64(i/10) * 10
+		polys = append(polys, NewPoly([]Point{{x, y}, {x + 5, y},
+			{x + 5, y + 5}, {x, y + 5}, {x, y}}, nil, nil))
+	}
+	mpoly := NewMultiPoly(append([]*Poly(nil), polys...))
+	plain := &MultiPoly{Polys: polys}
+	expect(t, mpoly.indexed() && !plain.indexed())
+	expect(t, mpoly.Rect() == R(0, 0, 95, 95))
+	for x := -2.5; x < 100; x += 2.5 {
+		for y := -2.5; y < 100; y += 2.5 {
+			expect(t, mpoly.ContainsPoint(P(x, y)) ==
+				plain.ContainsPoint(P(x, y)))
+			rect := R(x, y, x+3, y+3)
+			expect(t, mpoly.IntersectsRect(rect) == plain.IntersectsRect(rect))
+		}
+	}
+	// replacing a polygon is seen by the queries
+	mpoly.Polys[0] = NewPoly([]Point{{-20, -20}, {-10, -20}, {-10, -10},
+		{-20, -10}, {-20, -20}}, nil, nil)
+	expect(t, !mpoly.indexed())
+	expect(t, !mpoly.ContainsPoint(P(2, 2)))
+	expect(t, mpoly.ContainsPoint(P(-15, -15)))
+	expect(t, mpoly.Rect() == R(-20, -20, 95, 95))
+	// so is appending a polygon
+	mpoly = NewMultiPoly(polys[:50:50])
+	mpoly.Polys = append(mpoly.Polys, polys[99])
+	expect(t, mpoly.ContainsPoint(P(92, 92)))
+}
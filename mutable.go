@@ -0,0 +1,216 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// MutableSeries is an open series of points that can grow by appending points
+// to the end.
+//
+// The segment index is maintained as an uncompressed quadtree, which each new
+// segment is inserted into, and the compressed form that is used for
+// searching is only rebuilt when the series is searched after being modified.
+// This trades memory, since both forms of the index are kept, for appending
+// speed.
+//
+// The quadtree bounds are larger than the series, to allow for it to grow,
+// so the functions that expect an index over the rectangle of the series,
+// such as DistanceToSeries, KNearestSegments, Clone, and AppendBinary, use a
+// copy of the series with such an index instead. The copy is built when it's
+// first needed after the series is modified.
+//
+// A MutableSeries is not safe for concurrent use, even when only searching.
+type MutableSeries struct {
+	baseSeries
+	opts   IndexOptions
+	root   *qNode      // uncompressed quadtree
+	bounds Rect        // quadtree bounds
+	qindex []byte      // compressed quadtree
+	dirty  bool        // the compressed quadtree needs to be rebuilt
+	stale  bool        // the convex and clockwise flags need to be recalculated
+	snap   *baseSeries // copy with an index over the rect, see base
+}
+
+var _ Series = &MutableSeries{}
+
+// NewMutableSeries creates a new MutableSeries
+func NewMutableSeries(points []Point, opts *IndexOptions) *MutableSeries {
+	if opts == nil {
+		opts = DefaultIndexOptions
+	}
+	ms := new(MutableSeries)
+	ms.opts = *opts
+	ms.baseSeries = makeSeries(points, true, false, NoIndexing)
+	ms.maybeBuildTree()
+	return ms
+}
+
+// Append a point to the end of the series
+func (ms *MutableSeries) Append(point Point) {
+	ms.points = append(ms.points, point)
+	ms.snap = nil
+	switch {
+	case len(ms.points) == 2:
+		ms.rect = Rect{ms.points[0], ms.points[0]}.Union(Rect{point, point})
+	case len(ms.points) > 2:
+		ms.rect = ms.rect.Union(Rect{point, point})
+	}
+	ms.stale = true
+	if ms.root == nil {
+		ms.maybeBuildTree()
+		return
+	}
+	idx := len(ms.points) - 2
+	rect := ms.SegmentAt(idx).Rect()
+	if ms.bounds.ContainsRect(rect) {
+		ms.root.insert(&ms.baseSeries, ms.bounds, rect, idx, 0,
+			qLimitsFromOpts(&ms.opts))
+	} else {
+		ms.buildTree()
+	}
+	ms.dirty = true
+}
+
+func (ms *MutableSeries) maybeBuildTree() {
+	if ms.opts.Kind != None && ms.opts.MinPoints != 0 &&
+		len(ms.points) >= ms.opts.MinPoints {
+		ms.buildTree()
+	}
+}
+
+// buildTree rebuilds the uncompressed quadtree with bounds that are large
+// enough to allow for the series to grow before needing another rebuild.
+func (ms *MutableSeries) buildTree() {
+	pad := math.Max(ms.rect.Max.X-ms.rect.Min.X, ms.rect.Max.Y-ms.rect.Min.Y) / 2
+	ms.bounds = Rect{
+		Min: Point{ms.rect.Min.X - pad, ms.rect.Min.Y - pad},
+		Max: Point{ms.rect.Max.X + pad, ms.rect.Max.Y + pad},
+	}
+	ms.root = new(qNode)
+	limits := qLimitsFromOpts(&ms.opts)
+	n := ms.NumSegments()
+	for i := 0; i < n; i++ {
+		ms.root.insert(&ms.baseSeries, ms.bounds, ms.SegmentAt(i).Rect(), i, 0,
+			limits)
+	}
+	ms.dirty = true
+}
+
+// compress the quadtree, if needed
+func (ms *MutableSeries) compress() {
+	if !ms.dirty {
+		return
+	}
+	data := ms.root.compress([]byte{byte(ms.opts.Kind), 0, 0, 0, 0})
+	binary.LittleEndian.PutUint32(data[1:], uint32(len(data)))
+	ms.qindex = data
+	ms.dirty = false
+}
+
+// Index returns the compressed index, or nil if the series is not indexed.
+func (ms *MutableSeries) Index() []byte {
+	if ms.root == nil {
+		return nil
+	}
+	ms.compress()
+	return ms.qindex
+}
+
+// Convex returns true if the points create a convex linestring
+func (ms *MutableSeries) Convex() bool {
+	ms.process()
+	return ms.convex
+}
+
+// Clockwise returns true if the points move clockwise
+func (ms *MutableSeries) Clockwise() bool {
+	ms.process()
+	return ms.clockwise
+}
+
+func (ms *MutableSeries) process() {
+	if ms.stale {
+		ms.convex, ms.rect, ms.clockwise = processPoints(ms.points, false)
+		ms.stale = false
+	}
+}
+
+// Search for segments that intersect the provided rectangle
+func (ms *MutableSeries) Search(
+	rect Rect, iter func(seg Segment, idx int) bool,
+) {
+	if ms.root == nil {
+		ms.baseSeries.Search(rect, iter)
+		return
+	}
+	ms.compress()
+	qCompressSearch(ms.qindex, 5, &ms.baseSeries, ms.bounds, rect, iter)
+}
+
+// base returns a copy of the series with an index that is built over the
+// rectangle of the series, just like the index of a Line. The copy shares
+// the points with the series, and is kept until the series is modified.
+func (ms *MutableSeries) base() *baseSeries {
+	if ms.snap == nil {
+		opts := NoIndexing
+		if ms.root != nil {
+			opts = &ms.opts
+		}
+		points := ms.points[:len(ms.points):len(ms.points)]
+		snap := makeSeries(points, false, false, opts)
+		ms.snap = &snap
+	}
+	return ms.snap
+}
+
+// Recompute recalculates the convex, clockwise, and rect values of the series
+// from its current points, and rebuilds the quadtree. This is needed after
+// the points returned by RawPoints are modified.
+func (ms *MutableSeries) Recompute() {
+	ms.convex, ms.rect, ms.clockwise = processPoints(ms.points, false)
+	ms.stale = false
+	ms.snap = nil
+	if ms.root != nil {
+		ms.buildTree()
+	}
+}
+
+// Clone returns a deep copy of the series, which is also a MutableSeries.
+func (ms *MutableSeries) Clone() Series {
+	return NewMutableSeries(ms.points, &ms.opts)
+}
+
+// AppendBinary appends the binary representation of the series to dst and
+// returns the extended buffer. The index that is encoded is the one over the
+// rectangle of the series, so it can be restored with ParseSeriesBinary.
+func (ms *MutableSeries) AppendBinary(dst []byte, opts *BinaryOptions) []byte {
+	return ms.base().AppendBinary(dst, opts)
+}
+
+// Move returns a new series with every point moved by the deltas.
+func (ms *MutableSeries) Move(deltaX, deltaY float64) Series {
+	return ms.base().Move(deltaX, deltaY)
+}
+
+// Transform returns a new series with the affine transformation applied to
+// every point.
+func (ms *MutableSeries) Transform(m Affine) Series {
+	return ms.base().Transform(m)
+}
+
+// CountSearchBatch returns the number of segments that intersect each of the
+// provided rectangles.
+func (ms *MutableSeries) CountSearchBatch(rects []Rect) []int {
+	return ms.base().CountSearchBatch(rects)
+}
+
+// CoverageCounts returns the number of segments that are fully inside of the
+// rectangle, and the number of segments that cross its boundary.
+func (ms *MutableSeries) CoverageCounts(rect Rect) (inside, crossing int) {
+	return ms.base().CoverageCounts(rect)
+}
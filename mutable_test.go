@@ -0,0 +1,127 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+func searchIndexes(t testing.TB, series Series, rect Rect) []int {
+	var idxs []int
+	series.Search(rect, func(seg Segment, idx int) bool {
+		expect(t, seg == series.SegmentAt(idx))
+		idxs = append(idxs, idx)
+		return true
+	})
+	sort.Ints(idxs)
+	return idxs
+}
+
+func TestMutableSeriesAppend(t *testing.T) {
+	seed := time.Now().UnixNano()
+	rng := rand.New(rand.NewSource(seed))
+	N := 2000
+	points := make([]Point, N)
+	points[0] = P(rng.Float64()*360-180, rng.Float64()*180-90)
+	for i := 1; i < N; i++ {
+		points[i].X = points[i-1].X + rng.Float64() - 0.5
+		points[i].Y = points[i-1].Y + rng.Float64() - 0.5
+	}
+	ms := NewMutableSeries(nil, DefaultIndexOptions)
+	expect(t, ms.Empty())
+	for i := 0; i < N; i++ {
+		ms.Append(points[i])
+		if i%97 != 0 && i != N-1 {
+			continue
+		}
+		line := NewLine(points[:i+1], DefaultIndexOptions)
+		expect(t, ms.NumPoints() == line.NumPoints())
+		expect(t, ms.NumSegments() == line.NumSegments())
+		expect(t, ms.Rect() == line.Rect())
+		expect(t, ms.Convex() == line.Convex())
+		expect(t, ms.Clockwise() == line.Clockwise())
+		expect(t, (ms.Index() != nil) == (line.Index() != nil))
+		rect := line.Rect()
+		for j := 0; j < 20; j++ {
+			x := rect.Min.X + rng.Float64()*(rect.Max.X-rect.Min.X)
+			y := rect.Min.Y + rng.Float64()*(rect.Max.Y-rect.Min.Y)
+			query := R(x, y, x+rng.Float64()*2, y+rng.Float64()*2)
+			a, b := searchIndexes(t, ms, query), searchIndexes(t, line, query)
+			if len(a) != len(b) {
+				t.Fatalf("seed: %d, expected %d segments, got %d",
+					seed, len(b), len(a))
+			}
+			for k := range a {
+				expect(t, a[k] == b[k])
+			}
+		}
+	}
+}
+
+func TestMutableSeriesNoIndex(t *testing.T) {
+	ms := NewMutableSeries(octagon, NoIndexing)
+	expect(t, ms.Index() == nil)
+	ms.Append(P(5, 5))
+	expect(t, ms.Index() == nil)
+	expect(t, ms.NumPoints() == len(octagon)+1)
+	expect(t, len(searchIndexes(t, ms, R(4, 4, 6, 6))) == 1)
+	ms = NewMutableSeries(AZ, nil)
+	expect(t, ms.Index() != nil)
+}
+
+func TestMutableSeriesBase(t *testing.T) {
+	ms := NewMutableSeries(nil, DefaultIndexOptions)
+	for i := 0; i < 200; i++ {
+		ms.Append(P(float64(i%20), float64(i/20)+float64(i%3)/10))
+	}
+	line := NewLine(ms.RawPoints(), DefaultIndexOptions)
+	expect(t, seriesBase(ms) != nil)
+	expect(t, string(seriesBase(ms).Index()) == string(line.Index()))
+
+	clone := ms.Clone()
+	expect(t, len(clone.Index()) > 0)
+	expect(t, &clone.RawPoints()[0] != &ms.RawPoints()[0])
+	clone.(*MutableSeries).Append(P(50, 50))
+	expect(t, ms.NumPoints() == 200 && clone.NumPoints() == 201)
+
+	parsed, err := ParseSeriesBinary(ms.AppendBinary(nil, nil), false)
+	expect(t, err == nil)
+	expect(t, string(parsed.Index()) == string(line.Index()))
+	expect(t, len(ms.Move(1, 1).Index()) > 0)
+	expect(t, len(ms.Transform(Translate(1, 1)).Index()) > 0)
+
+	rects := []Rect{R(2, 2, 5, 5), R(10, 0, 12, 3), R(-5, -5, -1, -1)}
+	counts := ms.CountSearchBatch(rects)
+	for i, rect := range rects {
+		expect(t, counts[i] == len(searchIndexes(t, line, rect)))
+		expect(t, counts[i] == CountIntersectingSegments(ms, rect))
+		in1, cross1 := ms.CoverageCounts(rect)
+		in2, cross2 := line.CoverageCounts(rect)
+		expect(t, in1 == in2 && cross1 == cross2)
+	}
+	near1 := KNearestSegments(ms, 5,
+		func(rect Rect) float64 { return rect.DistanceToPoint(P(7, 3)) },
+		func(seg Segment) float64 { return seg.Distance(P(7, 3)) })
+	near2 := KNearestSegments(line, 5,
+		func(rect Rect) float64 { return rect.DistanceToPoint(P(7, 3)) },
+		func(seg Segment) float64 { return seg.Distance(P(7, 3)) })
+	expect(t, len(near1) == 5)
+	for i := range near1 {
+		expect(t, near1[i].Dist == near2[i].Dist)
+	}
+
+	// the copy is rebuilt after an append and after a recompute
+	ms.Append(P(30, 30))
+	expect(t, seriesBase(ms).NumPoints() == 201)
+	expect(t, CountIntersectingSegments(ms, R(25, 25, 35, 35)) == 1)
+	ms.RawPoints()[200] = P(-30, -30)
+	ms.Recompute()
+	expect(t, ms.Rect().Min == P(-30, -30))
+	expect(t, CountIntersectingSegments(ms, R(-35, -35, -25, -25)) == 1)
+	expect(t, len(searchIndexes(t, ms, R(-35, -35, -25, -25))) == 1)
+}
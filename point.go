@@ -4,6 +4,8 @@
 
 package geometry
 
+import "math"
+
 type Point struct {
 	X, Y float64
 }
@@ -12,6 +14,130 @@ func (point Point) Move(deltaX, deltaY float64) Point {
 	return Point{X: point.X + deltaX, Y: point.Y + deltaY}
 }
 
+// Rotate the point counter-clockwise around origin by radians.
+func (point Point) Rotate(origin Point, radians float64) Point {
+	sin, cos := math.Sincos(radians)
+	dx, dy := point.X-origin.X, point.Y-origin.Y
+	return Point{
+		X: origin.X + dx*cos - dy*sin,
+		Y: origin.Y + dx*sin + dy*cos,
+	}
+}
+
+// Distance returns the euclidean distance to other point.
+func (point Point) Distance(other Point) float64 {
+	return math.Hypot(other.X-point.X, other.Y-point.Y)
+}
+
+// DistanceSquared returns the squared euclidean distance to other point. It
+// avoids the square root, which makes it cheaper for comparing distances.
+func (point Point) DistanceSquared(other Point) float64 {
+	dx, dy := other.X-point.X, other.Y-point.Y
+	return dx*dx + dy*dy
+}
+
+// Lerp returns the point that is t of the way from the point to other
+// point, where a t of zero is the point and one is the other point.
+func (point Point) Lerp(other Point, t float64) Point {
+	return Point{
+		X: point.X + (other.X-point.X)*t,
+		Y: point.Y + (other.Y-point.Y)*t,
+	}
+}
+
+// Dot returns the dot product of the point and other point, where both
+// are treated as vectors.
+func (point Point) Dot(other Point) float64 {
+	return point.X*other.X + point.Y*other.Y
+}
+
+// HilbertIndex returns the position of the point along a Hilbert curve that
+// fills a grid of 2^order by 2^order cells covering the bounds. Points that
+// are near each other usually have positions that are near each other, which
+// makes it useful for sorting points into a cache-friendly order. Points
+// outside of the bounds are clamped to the edge of the grid. The order is
+// limited to between 1 and 32.
+func (point Point) HilbertIndex(order int, bounds Rect) uint64 {
+	o := curveOrder(order)
+	x, y := curveGridXY(point, bounds, o)
+	return hilbertXYToIndex(x, y, o)
+}
+
+// MortonIndex returns the position of the point along a Morton (Z-order)
+// curve that fills a grid of 2^order by 2^order cells covering the bounds.
+// The position interleaves the bits of the cell, with the X bit before the
+// Y bit. Points outside of the bounds are clamped to the edge of the grid.
+// The order is limited to between 1 and 32.
+func (point Point) MortonIndex(order int, bounds Rect) uint64 {
+	o := curveOrder(order)
+	x, y := curveGridXY(point, bounds, o)
+	return mortonXYToIndex(x, y, o)
+}
+
+func curveOrder(order int) uint {
+	if order < 1 {
+		return 1
+	}
+	if order > 32 {
+		return 32
+	}
+	return uint(order)
+}
+
+// curveGridXY returns the cell of the point in a grid of 2^order by 2^order
+// cells that covers the bounds. Points outside of the bounds are clamped to
+// the edge of the grid.
+func curveGridXY(point Point, bounds Rect, order uint) (x, y uint64) {
+	max := float64(uint64(1)<<order - 1)
+	cell := func(v, min, size float64) uint64 {
+		if size <= 0 || v <= min {
+			return 0
+		}
+		f := (v - min) / size * (max + 1)
+		if f >= max {
+			return uint64(max)
+		}
+		return uint64(f)
+	}
+	x = cell(point.X, bounds.Min.X, bounds.Max.X-bounds.Min.X)
+	y = cell(point.Y, bounds.Min.Y, bounds.Max.Y-bounds.Min.Y)
+	return x, y
+}
+
+// hilbertXYToIndex returns the position of the grid cell along the Hilbert
+// curve that fills a grid of 2^order by 2^order cells.
+func hilbertXYToIndex(x, y uint64, order uint) uint64 {
+	var d uint64
+	n := uint64(1) << order
+	for s := n >> 1; s > 0; s >>= 1 {
+		var rx, ry uint64
+		if x&s != 0 {
+			rx = 1
+		}
+		if y&s != 0 {
+			ry = 1
+		}
+		d += s * s * ((3 * rx) ^ ry)
+		if ry == 0 {
+			if rx == 1 {
+				x, y = n-1-x, n-1-y
+			}
+			x, y = y, x
+		}
+	}
+	return d
+}
+
+// mortonXYToIndex returns the position of the grid cell along the Morton
+// curve that fills a grid of 2^order by 2^order cells.
+func mortonXYToIndex(x, y uint64, order uint) uint64 {
+	var d uint64
+	for i := uint(0); i < order; i++ {
+		d |= (x>>i&1)<<(2*i+1) | (y>>i&1)<<(2*i)
+	}
+	return d
+}
+
 func (point Point) Empty() bool {
 	return false
 }
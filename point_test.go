@@ -5,6 +5,8 @@
 package geometry
 
 import (
+	"math"
+	"math/rand"
 	"testing"
 )
 
@@ -94,3 +96,132 @@ func TestPointIntersectsPoly(t *testing.T) {
 	expect(t, P(5, 5).IntersectsPoly(concave1))
 	expect(t, P(6, 6).IntersectsPoly(concave1))
 }
+
+func TestPointDistance(t *testing.T) {
+	expect(t, P(0, 0).Distance(P(3, 4)) == 5)
+	expect(t, P(3, 4).Distance(P(0, 0)) == 5)
+	expect(t, P(1, 1).Distance(P(1, 1)) == 0)
+}
+
+func TestPointRotate(t *testing.T) {
+	expect(t, pointsNear(P(1, 0).Rotate(P(0, 0), math.Pi/2), P(0, 1), 1e-12))
+	expect(t, pointsNear(P(1, 0).Rotate(P(0, 0), math.Pi), P(-1, 0), 1e-12))
+	expect(t, pointsNear(P(1, 0).Rotate(P(0, 0), -math.Pi/2), P(0, -1), 1e-12))
+	expect(t, pointsNear(P(3, 2).Rotate(P(2, 2), math.Pi/2), P(2, 3), 1e-12))
+	expect(t, P(3, 2).Rotate(P(2, 2), 0) == P(3, 2))
+	expect(t, P(2, 2).Rotate(P(2, 2), 1.234) == P(2, 2))
+	// agrees with the affine rotation about the origin
+	p := P(4.5, -1.25)
+	expect(t, pointsNear(p.Rotate(P(0, 0), 0.7), Rotation(0.7).Apply(p), 1e-12))
+}
+
+func TestPointDistanceSquared(t *testing.T) {
+	a, b := P(1, 2), P(4, 6)
+	expect(t, a.DistanceSquared(b) == 25)
+	expect(t, a.DistanceSquared(b) == a.Distance(b)*a.Distance(b))
+	expect(t, b.DistanceSquared(a) == 25)
+	expect(t, a.DistanceSquared(a) == 0)
+	c := P(-2.5, 7.25)
+	expect(t, math.Abs(a.DistanceSquared(c)-a.Distance(c)*a.Distance(c)) <
+		1e-12)
+}
+
+func TestPointLerp(t *testing.T) {
+	a, b := P(1, 2), P(5, -6)
+	expect(t, a.Lerp(b, 0) == a)
+	expect(t, a.Lerp(b, 1) == b)
+	expect(t, a.Lerp(b, 0.5) == P(3, -2))
+	expect(t, a.Lerp(b, 2) == P(9, -14))
+}
+
+func TestPointDot(t *testing.T) {
+	expect(t, P(1, 2).Dot(P(3, 4)) == 11)
+	expect(t, P(1, 0).Dot(P(0, 1)) == 0)
+	expect(t, P(-2, 3).Dot(P(-2, 3)) == 13)
+}
+
+func TestHilbertXYToIndex(t *testing.T) {
+	// order 1 visits the cells as (0,0) (0,1) (1,1) (1,0)
+	expect(t, hilbertXYToIndex(0, 0, 1) == 0)
+	expect(t, hilbertXYToIndex(0, 1, 1) == 1)
+	expect(t, hilbertXYToIndex(1, 1, 1) == 2)
+	expect(t, hilbertXYToIndex(1, 0, 1) == 3)
+	// every cell gets a unique position, and consecutive positions are
+	// neighboring cells
+	const order = 4
+	const n = 1 << order
+	cells := make([][2]uint64, n*n)
+	seen := make([]bool, n*n)
+	for x := uint64(0); x < n; x++ {
+		for y := uint64(0); y < n; y++ {
+			d := hilbertXYToIndex(x, y, order)
+			expect(t, d < n*n && !seen[d])
+			seen[d] = true
+			cells[d] = [2]uint64{x, y}
+		}
+	}
+	for d := 1; d < n*n; d++ {
+		dx := math.Abs(float64(cells[d][0]) - float64(cells[d-1][0]))
+		dy := math.Abs(float64(cells[d][1]) - float64(cells[d-1][1]))
+		expect(t, dx+dy == 1)
+	}
+}
+
+func TestPointHilbertIndex(t *testing.T) {
+	bounds := R(0, 0, 100, 100)
+	// order 1 has the corners of the bounds in curve order
+	expect(t, P(10, 10).HilbertIndex(1, bounds) == 0)
+	expect(t, P(10, 90).HilbertIndex(1, bounds) == 1)
+	expect(t, P(90, 90).HilbertIndex(1, bounds) == 2)
+	expect(t, P(90, 10).HilbertIndex(1, bounds) == 3)
+	// nearby points usually have nearby values
+	rng := rand.New(rand.NewSource(1))
+	var near, far float64
+	for i := 0; i < 1000; i++ {
+		a := P(rng.Float64()*100, rng.Float64()*100)
+		b := P(a.X+rng.Float64()*0.1, a.Y+rng.Float64()*0.1)
+		c := P(rng.Float64()*100, rng.Float64()*100)
+		ha := float64(a.HilbertIndex(16, bounds))
+		near += math.Abs(ha - float64(b.HilbertIndex(16, bounds)))
+		far += math.Abs(ha - float64(c.HilbertIndex(16, bounds)))
+	}
+	expect(t, near*100 < far)
+	// clamped to the grid
+	expect(t, P(-50, -50).HilbertIndex(8, bounds) == 0)
+	expect(t, P(500, -50).HilbertIndex(8, bounds) ==
+		P(100, 0).HilbertIndex(8, bounds))
+	// order is limited
+	expect(t, P(90, 10).HilbertIndex(0, bounds) == 3)
+	expect(t, P(100, 100).HilbertIndex(64, bounds) ==
+		P(100, 100).HilbertIndex(32, bounds))
+}
+
+func TestPointMortonIndex(t *testing.T) {
+	// reference with the bits interleaved by hand
+	bounds := R(0, 0, 16, 16)
+	ref := func(x, y uint64) uint64 {
+		var d uint64
+		for i := 0; i < 4; i++ {
+			if x&(1<<i) != 0 {
+				d += 1 << (2*i + 1)
+			}
+			if y&(1<<i) != 0 {
+				d += 1 << (2 * i)
+			}
+		}
+		return d
+	}
+	for x := 0; x < 16; x++ {
+		for y := 0; y < 16; y++ {
+			point := P(float64(x)+0.5, float64(y)+0.5)
+			expect(t, point.MortonIndex(4, bounds) ==
+				ref(uint64(x), uint64(y)))
+		}
+	}
+	expect(t, P(5.5, 3.5).MortonIndex(4, bounds) == 0x27)
+	// clamped to the grid
+	expect(t, P(-1, -1).MortonIndex(4, bounds) == 0)
+	expect(t, P(99, 99).MortonIndex(4, bounds) == 0xff)
+	// empty bounds
+	expect(t, P(5, 5).MortonIndex(4, R(5, 5, 5, 5)) == 0)
+}
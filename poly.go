@@ -4,6 +4,13 @@
 
 package geometry
 
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
 type Poly struct {
 	Exterior Ring
 	Holes    []Ring
@@ -21,6 +28,51 @@ func NewPoly(exterior []Point, holes [][]Point, opts *IndexOptions) *Poly {
 	return poly
 }
 
+// ErrInvalidPoly is returned when creating a polygon from invalid rings.
+var ErrInvalidPoly = errors.New("invalid polygon")
+
+// NewPolyChecked is like NewPoly, but the rings are validated first. Rings
+// that do not repeat their first point at the end are closed, and a ring
+// with fewer than three distinct points returns an error that says which
+// ring failed. The provided point slices are not modified.
+func NewPolyChecked(exterior []Point, holes [][]Point, opts *IndexOptions,
+) (*Poly, error) {
+	ext, err := checkPolyRing(exterior)
+	if err != nil {
+		return nil, fmt.Errorf("%w: exterior %v", ErrInvalidPoly, err)
+	}
+	nholes := make([][]Point, len(holes))
+	for i, hole := range holes {
+		nholes[i], err = checkPolyRing(hole)
+		if err != nil {
+			return nil, fmt.Errorf("%w: hole %d %v", ErrInvalidPoly, i, err)
+		}
+	}
+	return NewPoly(ext, nholes, opts), nil
+}
+
+// checkPolyRing returns a closed copy of the ring points.
+func checkPolyRing(points []Point) ([]Point, error) {
+	distinct := make(map[Point]bool, 3)
+	for _, point := range points {
+		distinct[point] = true
+		if len(distinct) == 3 {
+			break
+		}
+	}
+	if len(distinct) < 3 {
+		return nil, fmt.Errorf("has %d distinct points, needs at least 3",
+			len(distinct))
+	}
+	closed := points[0] == points[len(points)-1]
+	ring := make([]Point, len(points), len(points)+1)
+	copy(ring, points)
+	if !closed {
+		ring = append(ring, points[0])
+	}
+	return ring, nil
+}
+
 func (poly *Poly) Clockwise() bool {
 	if poly == nil || poly.Exterior == nil {
 		return false
@@ -42,6 +94,104 @@ func (poly *Poly) Rect() Rect {
 	return poly.Exterior.Rect()
 }
 
+// Area returns the area of the polygon, which is the area of the exterior
+// minus the area of the holes. The winding direction of each ring does not
+// matter.
+func (poly *Poly) Area() float64 {
+	if poly.Empty() {
+		return 0
+	}
+	area := ringArea(poly.Exterior)
+	for _, hole := range poly.Holes {
+		area -= ringArea(hole)
+	}
+	return area
+}
+
+// PointOnSurface returns a point that is guaranteed to be inside of the
+// polygon and outside of all holes, which is useful for placing a label on a
+// concave or holed polygon where the center may fall outside.
+// A horizontal scanline is cast near the vertical center of the polygon, at a
+// height that avoids all vertices, and the middle of the widest interior span
+// along that line is returned. Returns the first exterior point when the
+// polygon has no area.
+func (poly *Poly) PointOnSurface() Point {
+	if poly.Empty() {
+		return Point{}
+	}
+	rect := poly.Rect()
+	rings := append([]Ring{poly.Exterior}, poly.Holes...)
+	center := (rect.Min.Y + rect.Max.Y) / 2
+	lo, hi := math.Inf(-1), math.Inf(+1)
+	for _, ring := range rings {
+		for i := 0; i < ring.NumPoints(); i++ {
+			y := ring.PointAt(i).Y
+			if y <= center && y > lo {
+				lo = y
+			} else if y > center && y < hi {
+				hi = y
+			}
+		}
+	}
+	y := center
+	if !math.IsInf(lo, 0) && !math.IsInf(hi, 0) {
+		y = (lo + hi) / 2
+	}
+	var xs []float64
+	scan := Rect{Point{rect.Min.X, y}, Point{rect.Max.X, y}}
+	for _, ring := range rings {
+		ring.Search(scan, func(seg Segment, _ int) bool {
+			if (seg.A.Y > y) != (seg.B.Y > y) {
+				xs = append(xs, seg.A.X+
+					(y-seg.A.Y)*(seg.B.X-seg.A.X)/(seg.B.Y-seg.A.Y))
+			}
+			return true
+		})
+	}
+	sort.Float64s(xs)
+	point := poly.Exterior.PointAt(0)
+	var width float64
+	for i := 0; i+1 < len(xs); i += 2 {
+		if xs[i+1]-xs[i] > width {
+			width = xs[i+1] - xs[i]
+			point = Point{(xs[i] + xs[i+1]) / 2, y}
+		}
+	}
+	return point
+}
+
+// Simplify returns a new polygon where the exterior and each hole are
+// independently simplified using the Ramer-Douglas-Peucker algorithm, with
+// points that are farther than epsilon from the simplified ring retained.
+// Holes that are reduced to fewer than three distinct points are dropped.
+// The rings are indexed in the same manner as the original rings.
+func (poly *Poly) Simplify(epsilon float64) *Poly {
+	if poly == nil {
+		return nil
+	}
+	if poly.Exterior == nil {
+		return new(Poly)
+	}
+	simplify := func(ring Ring) []Point {
+		return simplifyPoints(seriesCopyPoints(ring), epsilon, nil)
+	}
+	npoly := new(Poly)
+	npoly.Exterior = newRing(simplify(poly.Exterior),
+		seriesIndexOptions(poly.Exterior))
+	for _, hole := range poly.Holes {
+		points := simplify(hole)
+		n := len(points)
+		if n > 0 && points[0] == points[n-1] {
+			n--
+		}
+		if n >= 3 {
+			npoly.Holes = append(npoly.Holes,
+				newRing(points, seriesIndexOptions(hole)))
+		}
+	}
+	return npoly
+}
+
 // Move the polygon by delta. Returns a new polygon
 func (poly *Poly) Move(deltaX, deltaY float64) *Poly {
 	if poly == nil {
@@ -100,6 +250,56 @@ func (poly *Poly) ContainsPoint(point Point) bool {
 	return contains
 }
 
+// ContainsPointOpts returns true if the polygon contains a point. When
+// includeBoundary is true, a point that is directly on the edge of the
+// exterior or the edge of a hole is contained, otherwise it's not.
+func (poly *Poly) ContainsPointOpts(point Point, includeBoundary bool) bool {
+	if poly == nil || poly.Exterior == nil {
+		return false
+	}
+	res := ringContainsPoint(poly.Exterior, point, includeBoundary)
+	if !res.hit {
+		return false
+	}
+	if res.idx != -1 {
+		// on the exterior edge
+		return true
+	}
+	for _, hole := range poly.Holes {
+		res := ringContainsPoint(hole, point, true)
+		if res.hit {
+			if res.idx != -1 {
+				// on the hole edge
+				return includeBoundary
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsPointWinding returns true if the polygon contains a point using the
+// nonzero winding rule, rather than the even-odd rule used by ContainsPoint.
+// Regions of a self-intersecting ring that are wound more than once are
+// considered inside. A point inside of a hole, using the same rule, is not
+// contained.
+func (poly *Poly) ContainsPointWinding(point Point) bool {
+	if poly == nil || poly.Exterior == nil {
+		return false
+	}
+	winding, onEdge := ringWindingNumber(poly.Exterior, point)
+	if winding == 0 && !onEdge {
+		return false
+	}
+	for _, hole := range poly.Holes {
+		winding, onEdge := ringWindingNumber(hole, point)
+		if winding != 0 && !onEdge {
+			return false
+		}
+	}
+	return true
+}
+
 func (poly *Poly) IntersectsPoint(point Point) bool {
 	if poly == nil {
 		return false
@@ -115,12 +315,53 @@ func (poly *Poly) ContainsRect(rect Rect) bool {
 	return poly.ContainsPoly(&Poly{Exterior: rect})
 }
 
+// IntersectsRect returns true if the polygon intersects the rect.
+// The exterior index is searched for a segment that intersects the rect,
+// and only when there is none are the containment cases checked, where
+// either the rect is inside of the exterior, or the exterior is inside of the
+// rect. A rect that is fully inside of a hole does not intersect.
 func (poly *Poly) IntersectsRect(rect Rect) bool {
-	if poly == nil {
+	if poly == nil || poly.Exterior == nil ||
+		poly.Exterior.NumPoints() == 0 ||
+		!poly.Exterior.Rect().IntersectsRect(rect) {
 		return false
 	}
-	// convert rect into a polygon
-	return poly.IntersectsPoly(&Poly{Exterior: rect})
+	if ringSegmentIntersectsRect(poly.Exterior, rect) {
+		return true
+	}
+	if rect.ContainsPoint(poly.Exterior.PointAt(0)) {
+		// exterior is inside of the rect
+		return true
+	}
+	if !ringContainsPoint(poly.Exterior, rect.Min, true).hit {
+		// rect is outside of the exterior
+		return false
+	}
+	// rect is inside of the exterior
+	for _, hole := range poly.Holes {
+		if hole.NumPoints() == 0 || !hole.Rect().IntersectsRect(rect) {
+			continue
+		}
+		if ringSegmentIntersectsRect(hole, rect) {
+			return true
+		}
+		if ringContainsPoint(hole, rect.Min, false).hit {
+			// rect is inside of the hole
+			return false
+		}
+	}
+	return true
+}
+
+// ringSegmentIntersectsRect returns true if any segment of the ring
+// intersects the rect.
+func ringSegmentIntersectsRect(ring Ring, rect Rect) bool {
+	var hit bool
+	ring.Search(rect, func(seg Segment, _ int) bool {
+		hit = rect.IntersectsSegment(seg)
+		return !hit
+	})
+	return hit
 }
 
 func (poly *Poly) ContainsLine(line *Line) bool {
@@ -203,3 +444,149 @@ func (poly *Poly) IntersectsPoly(other *Poly) bool {
 	}
 	return true
 }
+
+// SignedDistance returns the distance from the point to the nearest edge of
+// the exterior or a hole, which is negative when the polygon contains the
+// point and positive when it does not. Points on an edge return zero. The
+// index of each ring is used to find the nearest edge. Returns NaN for an
+// empty polygon.
+func (poly *Poly) SignedDistance(point Point) float64 {
+	if poly.Empty() {
+		return math.NaN()
+	}
+	_, _, dist := DistanceToSeriesMetric(poly.Exterior, EuclideanMetric(point))
+	for _, hole := range poly.Holes {
+		if hole.Rect().DistanceToPoint(point) >= dist {
+			continue
+		}
+		_, _, holeDist := DistanceToSeriesMetric(hole, EuclideanMetric(point))
+		if holeDist < dist {
+			dist = holeDist
+		}
+	}
+	if dist > 0 && poly.ContainsPoint(point) {
+		return -dist
+	}
+	return dist
+}
+
+// NearestBoundaryPoint returns the point on the boundary of the polygon that
+// is nearest to the provided point, along with the ring that it's on and the
+// distance to it. The ringIdx is -1 for the exterior, or the index of the
+// hole. The index of each ring is used, and holes whose rectangle is farther
+// away than the nearest point so far are skipped. When more than one ring is
+// equally near, the exterior, then the lowest hole, is returned. Returns NaN
+// for the distance if the polygon is empty.
+func (poly *Poly) NearestBoundaryPoint(point Point) (onBoundary Point,
+	ringIdx int, dist float64) {
+	if poly.Empty() {
+		return Point{}, -1, math.NaN()
+	}
+	seg, _, dist := DistanceToSeriesMetric(poly.Exterior,
+		EuclideanMetric(point))
+	onBoundary, ringIdx = seg.ClosestPoint(point), -1
+	for i, hole := range poly.Holes {
+		if hole.Rect().DistanceToPoint(point) >= dist {
+			continue
+		}
+		seg, _, holeDist := DistanceToSeriesMetric(hole, EuclideanMetric(point))
+		if holeDist < dist {
+			onBoundary, ringIdx, dist = seg.ClosestPoint(point), i, holeDist
+		}
+	}
+	return onBoundary, ringIdx, dist
+}
+
+// Covers returns true if no point of the other geometry is outside of the
+// polygon. Points on the boundary, including the edges of the holes, are
+// covered. Unlike the OGC Contains predicate, the other geometry does not
+// need to reach the interior of the polygon, so a line that lies along an
+// edge is covered. Point, Rect, Line, Poly, MultiLine, and MultiPoly are
+// supported. Returns false for other types, and for nil or empty geometries.
+func (poly *Poly) Covers(other Geometry) bool {
+	switch other := other.(type) {
+	case Point:
+		return poly.ContainsPointOpts(other, true)
+	case Rect:
+		return poly.ContainsRect(other)
+	case *Line:
+		return other != nil && !other.Empty() && poly.ContainsLine(other)
+	case *Poly:
+		return !other.Empty() && poly.ContainsPoly(other)
+	case *MultiLine:
+		if other.Empty() {
+			return false
+		}
+		for _, line := range other.Lines {
+			if line != nil && !line.Empty() && !poly.ContainsLine(line) {
+				return false
+			}
+		}
+		return true
+	case *MultiPoly:
+		if other.Empty() {
+			return false
+		}
+		for _, other := range other.Polys {
+			if !other.Empty() && !poly.ContainsPoly(other) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// IsValid returns true if the polygon is valid. A valid polygon has a closed,
+// non-self-intersecting exterior, and holes that are closed,
+// non-self-intersecting, inside of the exterior, and do not overlap each
+// other. When the polygon is not valid, the reason for the first failure is
+// returned.
+func (poly *Poly) IsValid() (bool, string) {
+	if poly.Empty() {
+		return false, "exterior is empty"
+	}
+	if !poly.Exterior.Closed() {
+		return false, "exterior is not closed"
+	}
+	if !IsSimple(poly.Exterior) {
+		return false, "exterior is self-intersecting"
+	}
+	for i, hole := range poly.Holes {
+		if hole.Empty() {
+			return false, fmt.Sprintf("hole %d is empty", i)
+		}
+		if !hole.Closed() {
+			return false, fmt.Sprintf("hole %d is not closed", i)
+		}
+		if !IsSimple(hole) {
+			return false, fmt.Sprintf("hole %d is self-intersecting", i)
+		}
+		if !ringContainsRing(poly.Exterior, hole, true) {
+			return false, fmt.Sprintf("hole %d is not inside of the exterior", i)
+		}
+	}
+	for i := 0; i < len(poly.Holes); i++ {
+		for j := i + 1; j < len(poly.Holes); j++ {
+			if ringIntersectsRing(poly.Holes[i], poly.Holes[j], false) {
+				return false, fmt.Sprintf("hole %d overlaps hole %d", i, j)
+			}
+		}
+	}
+	return true, ""
+}
+
+// MinusRect returns the parts of the polygon that are outside of the rect.
+// This may yield several polygons, such as when a band is removed from the
+// middle of the polygon, and a rect in the middle of the polygon becomes a
+// new hole. The parts are found with Difference, so holes that cross the edge
+// of the rect become part of the outline of the polygon that they are in.
+func (poly *Poly) MinusRect(rect Rect) []*Poly {
+	if poly.Empty() {
+		return nil
+	}
+	if !poly.Rect().IntersectsRect(rect) {
+		return []*Poly{poly}
+	}
+	return poly.Difference(&Poly{Exterior: rect}).Polys
+}
@@ -5,7 +5,12 @@
 package geometry
 
 import (
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
 	"testing"
+	"time"
 )
 
 func newPolyIndexed(exterior []Point, holes [][]Point) *Poly {
@@ -103,6 +108,31 @@ func TestPolyContainsPoint(t *testing.T) {
 	})
 }
 
+func TestPolyContainsPointWinding(t *testing.T) {
+	// pentagram, the center pentagon is wound twice
+	star := []Point{{0, 10}, {6, -8}, {-10, 3}, {10, 3}, {-6, -8}, {0, 10}}
+	dualPolyTest(t, star, nil, func(t *testing.T, poly *Poly) {
+		expect(t, !poly.ContainsPoint(P(0, 0)))
+		expect(t, poly.ContainsPointWinding(P(0, 0)))
+		expect(t, poly.ContainsPoint(P(0, 8)))
+		expect(t, poly.ContainsPointWinding(P(0, 8)))
+		expect(t, poly.ContainsPointWinding(P(0, 10)))
+		expect(t, poly.ContainsPointWinding(P(-10, 3)))
+		expect(t, !poly.ContainsPointWinding(P(9, -8)))
+		expect(t, !poly.ContainsPointWinding(P(20, 0)))
+	})
+	small := []Point{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}}
+	dualPolyTest(t, octagon, [][]Point{small}, func(t *testing.T, poly *Poly) {
+		expect(t, poly.ContainsPointWinding(P(1, 5)))
+		expect(t, poly.ContainsPointWinding(P(0, 5)))
+		expect(t, !poly.ContainsPointWinding(P(5, 5)))
+		expect(t, poly.ContainsPointWinding(P(4, 5)))
+		expect(t, !poly.ContainsPointWinding(P(11, 5)))
+	})
+	var poly *Poly
+	expect(t, !poly.ContainsPointWinding(P(0, 0)))
+}
+
 func TestPolyIntersectsPoint(t *testing.T) {
 	small := []Point{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}}
 	dualPolyTest(t, octagon, [][]Point{small}, func(t *testing.T, poly *Poly) {
@@ -141,6 +171,29 @@ func TestPolyIntersectsRect(t *testing.T) {
 		expect(t, !poly.IntersectsRect(R(0, 0, 1.4, 1.4)))
 		expect(t, poly.IntersectsRect(R(0, 0, 1.5, 1.5)))
 		expect(t, !poly.IntersectsRect(R(0, 0, 10, 10).Move(11, 0)))
+		// straddling the exterior edge
+		expect(t, poly.IntersectsRect(R(9, 4, 12, 6)))
+		// containing the entire polygon
+		expect(t, poly.IntersectsRect(R(-1, -1, 11, 11)))
+		// straddling and touching the hole edge
+		expect(t, poly.IntersectsRect(R(5, 5, 7, 7)))
+		expect(t, poly.IntersectsRect(R(4.5, 4.5, 6, 5.5)))
+	})
+	// the fast path agrees with intersecting the rect as a polygon
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	hole := []Point{{-112, 33}, {-111, 33}, {-111, 34}, {-112, 34}, {-112, 33}}
+	dualPolyTest(t, AZ, [][]Point{hole}, func(t *testing.T, poly *Poly) {
+		bounds := poly.Rect()
+		for i := 0; i < 1000; i++ {
+			x := bounds.Min.X - 1 + rng.Float64()*(bounds.Max.X-bounds.Min.X+2)
+			y := bounds.Min.Y - 1 + rng.Float64()*(bounds.Max.Y-bounds.Min.Y+2)
+			size := rng.Float64() * 0.5
+			rect := R(x, y, x+size, y+size)
+			expect(t, poly.IntersectsRect(rect) ==
+				poly.IntersectsPoly(&Poly{Exterior: rect}))
+		}
+		expect(t, !poly.IntersectsRect(R(-111.8, 33.2, -111.2, 33.8)))
+		expect(t, poly.IntersectsRect(R(-111.8, 33.2, -110.8, 33.8)))
 	})
 }
 
@@ -249,3 +302,374 @@ func Test369(t *testing.T) {
 	expect(t, !b.IntersectsPoly(polyHoles))
 	expect(t, !c.IntersectsPoly(polyHoles))
 }
+
+func TestPolyIsValid(t *testing.T) {
+	square := []Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	hole1 := []Point{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}}
+	hole2 := []Point{{6, 6}, {8, 6}, {8, 8}, {6, 8}, {6, 6}}
+	hole3 := []Point{{3, 3}, {5, 3}, {5, 5}, {3, 5}, {3, 3}}
+	poking := []Point{{8, 4}, {12, 4}, {12, 6}, {8, 6}, {8, 4}}
+	figure8 := []Point{{2, 2}, {4, 4}, {4, 2}, {2, 4}, {2, 2}}
+	test := func(exterior []Point, holes [][]Point, valid bool, reason string) {
+		t.Helper()
+		dualPolyTest(t, exterior, holes, func(t *testing.T, poly *Poly) {
+			t.Helper()
+			ok, why := poly.IsValid()
+			if ok != valid || why != reason {
+				t.Fatalf("expected (%t, %q), got (%t, %q)", valid, reason, ok, why)
+			}
+		})
+	}
+	test(square, nil, true, "")
+	test(octagon, [][]Point{hole1, hole2}, true, "")
+	test(AZ, nil, true, "")
+	test(square, [][]Point{hole1, poking}, false,
+		"hole 1 is not inside of the exterior")
+	test(square, [][]Point{hole1, hole2, hole3}, false,
+		"hole 0 overlaps hole 2")
+	test(square, [][]Point{figure8}, false, "hole 0 is self-intersecting")
+	test([]Point{{0, 0}, {10, 10}, {10, 0}, {0, 10}, {0, 0}}, nil, false,
+		"exterior is self-intersecting")
+	test(square, [][]Point{{{1, 1}, {2, 2}}}, false, "hole 0 is empty")
+
+	var poly *Poly
+	ok, why := poly.IsValid()
+	expect(t, !ok && why == "exterior is empty")
+	ok, why = (&Poly{Exterior: R(0, 0, 10, 10)}).IsValid()
+	expect(t, ok && why == "")
+}
+
+func TestPolyMinusRect(t *testing.T) {
+	square := []Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	area := func(polys []*Poly) float64 {
+		var area float64
+		for _, poly := range polys {
+			area += poly.Area()
+		}
+		return area
+	}
+	dualPolyTest(t, square, nil, func(t *testing.T, poly *Poly) {
+		// middle horizontal band
+		polys := poly.MinusRect(R(-5, 4, 15, 6))
+		expect(t, len(polys) == 2)
+		expect(t, polys[0].Rect() == R(0, 0, 10, 4))
+		expect(t, polys[1].Rect() == R(0, 6, 10, 10))
+		expect(t, area(polys) == 80)
+		// corner
+		polys = poly.MinusRect(R(5, 5, 15, 15))
+		expect(t, len(polys) == 1 && len(polys[0].Holes) == 0)
+		expect(t, area(polys) == 75)
+		// center
+		polys = poly.MinusRect(R(4, 4, 6, 6))
+		expect(t, len(polys) == 1 && len(polys[0].Holes) == 1)
+		expect(t, area(polys) == 96)
+		expect(t, !polys[0].ContainsPoint(P(5, 5)))
+		// everything
+		expect(t, len(poly.MinusRect(R(-1, -1, 11, 11))) == 0)
+		// nothing
+		polys = poly.MinusRect(R(20, 20, 30, 30))
+		expect(t, len(polys) == 1 && polys[0] == poly)
+	})
+	hole := []Point{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}
+	dualPolyTest(t, square, [][]Point{hole}, func(t *testing.T, poly *Poly) {
+		polys := poly.MinusRect(R(-5, 4, 15, 6))
+		expect(t, len(polys) == 2)
+		expect(t, len(polys[0].Holes) == 1 && len(polys[1].Holes) == 0)
+		expect(t, area(polys) == 76)
+		// the hole straddles the edge of the rect, and becomes part of the
+		// outline
+		polys = poly.MinusRect(R(2, 2, 15, 15))
+		expect(t, len(polys) == 1 && len(polys[0].Holes) == 0)
+		expect(t, area(polys) == 100-4-64+1)
+		valid, _ := polys[0].IsValid()
+		expect(t, valid)
+		expect(t, !polys[0].ContainsPoint(P(1.5, 1.5)))
+		expect(t, !polys[0].ContainsPoint(P(5, 5)))
+		expect(t, polys[0].ContainsPoint(P(1, 5)))
+		// the hole straddles the edges between the bands, and is joined
+		// with the rect into one hole
+		polys = poly.MinusRect(R(2, 2, 8, 5))
+		expect(t, len(polys) == 1 && len(polys[0].Holes) == 1)
+		valid, _ = polys[0].IsValid()
+		expect(t, valid)
+		expect(t, area(polys) == 100-4-18+1)
+	})
+	// a concave polygon that enters the rect twice
+	ushape := []Point{{0, 0}, {10, 0}, {10, 10}, {7, 10}, {7, 3}, {3, 3},
+		{3, 10}, {0, 10}, {0, 0}}
+	dualPolyTest(t, ushape, nil, func(t *testing.T, poly *Poly) {
+		polys := poly.MinusRect(R(-1, 5, 11, 11))
+		expect(t, len(polys) == 1 && len(polys[0].Holes) == 0)
+		expect(t, area(polys) == 50-8)
+		valid, _ := polys[0].IsValid()
+		expect(t, valid)
+	})
+	var poly *Poly
+	expect(t, poly.MinusRect(R(0, 0, 1, 1)) == nil)
+}
+
+func TestPolyArea(t *testing.T) {
+	square := []Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	hole := []Point{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}}
+	holeCW := []Point{{1, 1}, {1, 3}, {3, 3}, {3, 1}}
+	dualPolyTest(t, square, [][]Point{hole}, func(t *testing.T, poly *Poly) {
+		expect(t, poly.Area() == 96)
+	})
+	dualPolyTest(t, square, [][]Point{hole, holeCW}, func(t *testing.T, poly *Poly) {
+		expect(t, poly.Area() == 92)
+	})
+	dualPolyTest(t, octagon, nil, func(t *testing.T, poly *Poly) {
+		expect(t, poly.Area() == 82)
+	})
+	expect(t, (&Poly{Exterior: R(0, 0, 10, 5)}).Area() == 50)
+	var poly *Poly
+	expect(t, poly.Area() == 0)
+	expect(t, (&Poly{}).Area() == 0)
+}
+
+func TestPolyPointOnSurface(t *testing.T) {
+	cshape := []Point{{0, 0}, {10, 0}, {10, 2}, {2, 2}, {2, 8}, {10, 8},
+		{10, 10}, {0, 10}, {0, 0}}
+	dualPolyTest(t, cshape, nil, func(t *testing.T, poly *Poly) {
+		point := poly.PointOnSurface()
+		expect(t, point == P(1, 5))
+		expect(t, poly.ContainsPoint(point))
+	})
+	square := []Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	hole := []Point{{3, 3}, {7, 3}, {7, 7}, {3, 7}, {3, 3}}
+	dualPolyTest(t, square, [][]Point{hole}, func(t *testing.T, poly *Poly) {
+		point := poly.PointOnSurface()
+		expect(t, poly.ContainsPoint(point))
+		_, holeIndex := poly.ContainsPointEx(point)
+		expect(t, holeIndex == -1)
+		expect(t, !poly.ContainsPoint(P(5, 5)))
+	})
+	for _, points := range [][]Point{octagon, concave1, concave2, concave3,
+		concave4, AZ} {
+		dualPolyTest(t, points, nil, func(t *testing.T, poly *Poly) {
+			expect(t, poly.ContainsPoint(poly.PointOnSurface()))
+		})
+	}
+	rect := &Poly{Exterior: R(0, 0, 10, 4)}
+	expect(t, rect.PointOnSurface() == P(5, 2))
+	var poly *Poly
+	expect(t, poly.PointOnSurface() == P(0, 0))
+}
+
+func TestPolyContainsPointOpts(t *testing.T) {
+	square := []Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	hole := []Point{{3, 3}, {7, 3}, {7, 7}, {3, 7}, {3, 3}}
+	dualPolyTest(t, square, [][]Point{hole}, func(t *testing.T, poly *Poly) {
+		for _, includeBoundary := range []bool{true, false} {
+			// inside
+			expect(t, poly.ContainsPointOpts(P(1, 1), includeBoundary))
+			// outside
+			expect(t, !poly.ContainsPointOpts(P(11, 1), includeBoundary))
+			// inside of the hole
+			expect(t, !poly.ContainsPointOpts(P(5, 5), includeBoundary))
+			// on the shell
+			expect(t, poly.ContainsPointOpts(P(0, 5), includeBoundary) ==
+				includeBoundary)
+			expect(t, poly.ContainsPointOpts(P(10, 10), includeBoundary) ==
+				includeBoundary)
+			// on the hole edge
+			expect(t, poly.ContainsPointOpts(P(3, 5), includeBoundary) ==
+				includeBoundary)
+			expect(t, poly.ContainsPointOpts(P(7, 7), includeBoundary) ==
+				includeBoundary)
+		}
+		// the default behavior matches the boundary option
+		for x := -1.0; x <= 11; x += 0.5 {
+			for y := -1.0; y <= 11; y += 0.5 {
+				expect(t, poly.ContainsPoint(P(x, y)) ==
+					poly.ContainsPointOpts(P(x, y), true))
+			}
+		}
+	})
+	var poly *Poly
+	expect(t, !poly.ContainsPointOpts(P(0, 0), true))
+}
+
+func TestPolySimplify(t *testing.T) {
+	// a circle with many points and two holes, one tiny.
+	var exterior, hole, tiny []Point
+	for i := 0; i < 360; i++ {
+		sin, cos := math.Sincos(float64(i) * math.Pi / 180)
+		exterior = append(exterior, P(cos*100, sin*100))
+		if i%10 == 0 {
+			hole = append(hole, P(cos*20, sin*20))
+			tiny = append(tiny, P(50+cos*0.5, sin*0.5))
+		}
+	}
+	exterior = append(exterior, exterior[0])
+	hole = append(hole, hole[0])
+	tiny = append(tiny, tiny[0])
+	dualPolyTest(t, exterior, [][]Point{hole, tiny}, func(t *testing.T, poly *Poly) {
+		simple := poly.Simplify(0)
+		expect(t, simple.Exterior.NumPoints() == poly.Exterior.NumPoints())
+		expect(t, len(simple.Holes) == 2)
+		expect(t, len(simple.Exterior.Index()) > 0 ==
+			(len(poly.Exterior.Index()) > 0))
+
+		simple = poly.Simplify(1)
+		expect(t, simple.Exterior.NumPoints() < poly.Exterior.NumPoints())
+		expect(t, len(simple.Holes) == 1)
+		expect(t, simple.Holes[0].NumPoints() < len(hole))
+		expect(t, math.Abs(simple.Area()-poly.Area()) < poly.Area()*0.02)
+		expect(t, simple.ContainsPoint(P(0, 50)))
+		expect(t, !simple.ContainsPoint(P(0, 0)))
+
+		// a large epsilon leaves a coarse quad
+		simple = poly.Simplify(60)
+		expect(t, simple.Exterior.NumPoints() == 5)
+		expect(t, len(simple.Holes) == 0)
+	})
+	var poly *Poly
+	expect(t, poly.Simplify(1) == nil)
+	expect(t, (&Poly{}).Simplify(1).Empty())
+}
+
+func TestNewPolyChecked(t *testing.T) {
+	// unclosed exterior is closed
+	exterior := []Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	poly, err := NewPolyChecked(exterior, nil, nil)
+	expect(t, err == nil)
+	expect(t, len(exterior) == 4)
+	expect(t, poly.Exterior.NumPoints() == 5)
+	expect(t, CoordinatesClosed(poly.Exterior))
+	expect(t, poly.ContainsPoint(P(5, 5)))
+	// already closed rings are unchanged
+	hole := []Point{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}}
+	poly, err = NewPolyChecked(exterior, [][]Point{hole}, nil)
+	expect(t, err == nil)
+	expect(t, len(poly.Holes) == 1 && poly.Holes[0].NumPoints() == 5)
+	expect(t, !poly.ContainsPoint(P(3, 3)))
+	// a two point hole
+	_, err = NewPolyChecked(exterior, [][]Point{hole, {{5, 5}, {6, 6}}}, nil)
+	expect(t, errors.Is(err, ErrInvalidPoly))
+	expect(t, strings.Contains(err.Error(), "hole 1"))
+	// repeated points are not distinct
+	_, err = NewPolyChecked([]Point{{0, 0}, {1, 1}, {0, 0}, {1, 1}}, nil, nil)
+	expect(t, errors.Is(err, ErrInvalidPoly))
+	expect(t, strings.Contains(err.Error(), "exterior"))
+	_, err = NewPolyChecked(nil, nil, nil)
+	expect(t, errors.Is(err, ErrInvalidPoly))
+}
+
+func TestPolyCovers(t *testing.T) {
+	poly := NewPoly([]Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		[][]Point{{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}}}, nil)
+	// a line on the edge is covered, but it does not reach the interior,
+	// so it's not contained in the OGC sense
+	edge := L(P(0, 0), P(10, 0), P(10, 10))
+	expect(t, poly.Covers(edge))
+	for _, point := range []Point{P(5, 0), P(10, 5)} {
+		expect(t, poly.Covers(point))
+		expect(t, !poly.ContainsPointOpts(point, false))
+	}
+	holeEdge := L(P(2, 2), P(2, 4))
+	expect(t, poly.Covers(holeEdge))
+	expect(t, !poly.ContainsPointOpts(P(2, 3), false))
+	expect(t, !poly.Covers(L(P(1, 1), P(9, 9))))
+	expect(t, poly.Covers(L(P(1, 1), P(9, 1))))
+	expect(t, !poly.Covers(L(P(5, 5), P(15, 5))))
+	// points
+	expect(t, poly.Covers(P(5, 5)))
+	expect(t, poly.Covers(P(0, 0)))
+	expect(t, !poly.Covers(P(3, 3)))
+	expect(t, !poly.Covers(P(11, 5)))
+	// polygons and rects
+	expect(t, poly.Covers(R(0, 0, 2, 2)))
+	expect(t, poly.Covers(R(4, 2, 10, 10)))
+	expect(t, !poly.Covers(R(1, 1, 3, 3)))
+	expect(t, poly.Covers(poly))
+	expect(t, !poly.Covers(NewPoly([]Point{{5, 5}, {15, 5}, {15, 15},
+		{5, 5}}, nil, nil)))
+	// multis
+	expect(t, poly.Covers(NewMultiLine([]*Line{edge, holeEdge})))
+	expect(t, !poly.Covers(NewMultiLine([]*Line{
+		edge, L(P(5, 5), P(15, 5)),
+	})))
+	expect(t, poly.Covers(NewMultiPoly([]*Poly{{Exterior: R(0, 0, 1, 1)},
+		{Exterior: R(9, 9, 10, 10)}})))
+	expect(t, !poly.Covers(NewMultiPoly([]*Poly{{Exterior: R(0, 0, 1, 1)},
+		{Exterior: R(9, 9, 11, 11)}})))
+	// nil and empty
+	expect(t, !poly.Covers(nil))
+	expect(t, !poly.Covers((*Line)(nil)))
+	expect(t, !poly.Covers(NewLine(nil, nil)))
+	expect(t, !poly.Covers(new(Poly)))
+	expect(t, !poly.Covers(NewMultiLine(nil)))
+	expect(t, !poly.Covers(NewMultiPoly(nil)))
+	expect(t, !(*Poly)(nil).Covers(P(0, 0)))
+}
+
+func TestPolySignedDistance(t *testing.T) {
+	poly := NewPoly([]Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		[][]Point{{{4, 4}, {4, 6}, {6, 6}, {6, 4}, {4, 4}}}, nil)
+	// the sign flips across the bottom edge
+	expect(t, poly.SignedDistance(P(3, 1)) == -1)
+	expect(t, poly.SignedDistance(P(3, 0)) == 0)
+	expect(t, poly.SignedDistance(P(3, -1)) == 1)
+	// and across an edge of the hole
+	expect(t, poly.SignedDistance(P(3, 5)) == -1)
+	expect(t, poly.SignedDistance(P(4, 5)) == 0)
+	expect(t, poly.SignedDistance(P(4.5, 5)) == 0.5)
+	// nearest edge
+	expect(t, poly.SignedDistance(P(8, 5)) == -2)
+	expect(t, poly.SignedDistance(P(1.5, 9)) == -1)
+	expect(t, poly.SignedDistance(P(13, 14)) == 5)
+	// matches the perpendicular distance to the nearest edge
+	az := NewPoly(AZ, nil, DefaultIndexOptions)
+	rng := rand.New(rand.NewSource(1))
+	rect := az.Rect()
+	for i := 0; i < 1000; i++ {
+		p := P(rect.Min.X-1+rng.Float64()*(rect.Max.X-rect.Min.X+2),
+			rect.Min.Y-1+rng.Float64()*(rect.Max.Y-rect.Min.Y+2))
+		min := math.Inf(+1)
+		for j := 0; j < az.Exterior.NumSegments(); j++ {
+			min = math.Min(min, az.Exterior.SegmentAt(j).Distance(p))
+		}
+		dist := az.SignedDistance(p)
+		expect(t, math.Abs(math.Abs(dist)-min) < 1e-12)
+		expect(t, (dist < 0) == az.ContainsPoint(p))
+	}
+	expect(t, math.IsNaN(new(Poly).SignedDistance(P(0, 0))))
+}
+
+func TestPolyNearestBoundaryPoint(t *testing.T) {
+	exterior := []Point{{0, 0}, {100, 0}, {100, 100}, {0, 100}, {0, 0}}
+	holes := [][]Point{
+		{{10, 10}, {30, 10}, {30, 30}, {10, 30}, {10, 10}},
+		{{60, 60}, {80, 60}, {80, 80}, {60, 80}, {60, 60}},
+	}
+	poly := NewPoly(exterior, holes, nil)
+	// inside of the second hole, nearer to its edge than the exterior
+	point, ringIdx, dist := poly.NearestBoundaryPoint(P(70, 75))
+	expect(t, point == P(70, 80) && ringIdx == 1 && dist == 5)
+	point, ringIdx, dist = poly.NearestBoundaryPoint(P(12, 20))
+	expect(t, point == P(10, 20) && ringIdx == 0 && dist == 2)
+	// in the interior, nearer to the exterior
+	point, ringIdx, dist = poly.NearestBoundaryPoint(P(50, 3))
+	expect(t, point == P(50, 0) && ringIdx == -1 && dist == 3)
+	// outside of the polygon
+	point, ringIdx, dist = poly.NearestBoundaryPoint(P(110, 120))
+	expect(t, point == P(100, 100) && ringIdx == -1 &&
+		math.Abs(dist-math.Hypot(10, 20)) < 1e-12)
+	// on the boundary of a hole
+	point, ringIdx, dist = poly.NearestBoundaryPoint(P(30, 15))
+	expect(t, point == P(30, 15) && ringIdx == 0 && dist == 0)
+	// same distance as SignedDistance
+	for _, p := range []Point{{5, 5}, {20, 20}, {45, 70}, {-5, 50}} {
+		_, _, dist := poly.NearestBoundaryPoint(p)
+		expect(t, dist == math.Abs(poly.SignedDistance(p)))
+	}
+	// indexed rings
+	az := NewPoly(AZ, nil, DefaultIndexOptions)
+	p := az.Rect().Center()
+	point, ringIdx, dist = az.NearestBoundaryPoint(p)
+	expect(t, ringIdx == -1 && math.Abs(point.Distance(p)-dist) < 1e-12)
+	expect(t, dist == math.Abs(az.SignedDistance(p)))
+	_, ringIdx, dist = new(Poly).NearestBoundaryPoint(P(1, 1))
+	expect(t, ringIdx == -1 && math.IsNaN(dist))
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import "sort"
+
+// PolyIndex is a spatial index of many polygons, which is backed by an
+// R-tree over the rectangles of the polygons. It's useful for finding the
+// polygons that contain a point without testing each polygon.
+// The zero value is an empty index that is ready to use.
+type PolyIndex struct {
+	root *rtreeNode
+}
+
+const rtreeMaxEntries = 16
+
+type rtreeEntry struct {
+	rect Rect
+	node *rtreeNode // child node of a branch
+	poly *Poly      // polygon of a leaf
+}
+
+type rtreeNode struct {
+	leaf    bool
+	entries []rtreeEntry
+}
+
+// Insert adds the polygon to the index. Nil and empty polygons are ignored.
+func (index *PolyIndex) Insert(poly *Poly) {
+	if poly.Empty() {
+		return
+	}
+	entry := rtreeEntry{rect: poly.Rect(), poly: poly}
+	if index.root == nil {
+		index.root = &rtreeNode{leaf: true}
+	}
+	if split := index.root.insert(entry); split != nil {
+		// grow a new root
+		index.root = &rtreeNode{entries: []rtreeEntry{
+			{rect: index.root.rect(), node: index.root},
+			{rect: split.rect(), node: split},
+		}}
+	}
+}
+
+// insert adds the entry to the node, and returns the new sibling when the
+// node is split.
+func (node *rtreeNode) insert(entry rtreeEntry) *rtreeNode {
+	if node.leaf {
+		node.entries = append(node.entries, entry)
+	} else {
+		// choose the child that needs the least enlargement
+		best := 0
+		bestGrowth, bestArea := 0.0, 0.0
+		for i, child := range node.entries {
+			area := child.rect.Area()
+			growth := child.rect.Union(entry.rect).Area() - area
+			if i == 0 || growth < bestGrowth ||
+				(growth == bestGrowth && area < bestArea) {
+				best, bestGrowth, bestArea = i, growth, area
+			}
+		}
+		child := &node.entries[best]
+		split := child.node.insert(entry)
+		child.rect = child.node.rect()
+		if split != nil {
+			node.entries = append(node.entries,
+				rtreeEntry{rect: split.rect(), node: split})
+		}
+	}
+	if len(node.entries) <= rtreeMaxEntries {
+		return nil
+	}
+	return node.split()
+}
+
+// split moves half of the entries to a new sibling node. The entries are
+// divided along the axis where their centers are the most spread out.
+func (node *rtreeNode) split() *rtreeNode {
+	rect := node.rect()
+	center := func(i int, x bool) float64 {
+		r := node.entries[i].rect
+		if x {
+			return r.Min.X + r.Max.X
+		}
+		return r.Min.Y + r.Max.Y
+	}
+	byX := rect.Max.X-rect.Min.X >= rect.Max.Y-rect.Min.Y
+	sort.Slice(node.entries, func(i, j int) bool {
+		return center(i, byX) < center(j, byX)
+	})
+	half := len(node.entries) / 2
+	sibling := &rtreeNode{leaf: node.leaf}
+	sibling.entries = append(sibling.entries, node.entries[half:]...)
+	node.entries = append([]rtreeEntry(nil), node.entries[:half]...)
+	return sibling
+}
+
+// rect returns the union of the rectangles of all entries.
+func (node *rtreeNode) rect() Rect {
+	rect := node.entries[0].rect
+	for _, entry := range node.entries[1:] {
+		rect = rect.Union(entry.rect)
+	}
+	return rect
+}
+
+// Search calls iter for each polygon with a rectangle that intersects the
+// provided rectangle. Return false from iter to stop the search.
+func (index *PolyIndex) Search(rect Rect, iter func(poly *Poly) bool) {
+	if index.root != nil {
+		index.root.search(rect, iter)
+	}
+}
+
+func (node *rtreeNode) search(rect Rect, iter func(poly *Poly) bool) bool {
+	for _, entry := range node.entries {
+		if !entry.rect.IntersectsRect(rect) {
+			continue
+		}
+		if node.leaf {
+			if !iter(entry.poly) {
+				return false
+			}
+		} else if !entry.node.search(rect, iter) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainingPolys returns the polygons that contain the point.
+func (index *PolyIndex) ContainingPolys(point Point) []*Poly {
+	var polys []*Poly
+	index.Search(point.Rect(), func(poly *Poly) bool {
+		if poly.ContainsPoint(point) {
+			polys = append(polys, poly)
+		}
+		return true
+	})
+	return polys
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPolyIndex(t *testing.T) {
+	var index PolyIndex
+	expect(t, len(index.ContainingPolys(P(0, 0))) == 0)
+	index.Insert(nil)
+	index.Insert(new(Poly))
+	expect(t, index.root == nil)
+	polys := make([]*Poly, 1000)
+	for i := range polys {
+		x, y := rand.Float64()*100, rand.Float64()*100
+		size := 1 + rand.Float64()*10
+		polys[i] = NewPoly([]Point{{x, y}, {x + size, y},
+			{x + size, y + size}, {x, y + size}, {x, y}}, nil, nil)
+		index.Insert(polys[i])
+	}
+	for i := 0; i < 1000; i++ {
+		point := P(rand.Float64()*110, rand.Float64()*110)
+		found := make(map[*Poly]bool)
+		for _, poly := range index.ContainingPolys(point) {
+			expect(t, !found[poly])
+			found[poly] = true
+		}
+		var count int
+		for _, poly := range polys {
+			if poly.ContainsPoint(point) {
+				expect(t, found[poly])
+				count++
+			}
+		}
+		expect(t, count == len(found))
+	}
+	// search everything, and stop early
+	var count int
+	index.Search(R(-1, -1, 111, 111), func(poly *Poly) bool {
+		count++
+		return true
+	})
+	expect(t, count == len(polys))
+	count = 0
+	index.Search(R(-1, -1, 111, 111), func(poly *Poly) bool {
+		count++
+		return count < 10
+	})
+	expect(t, count == 10)
+}
@@ -14,14 +14,38 @@ import (
 const qMaxItems = 12
 const qMaxDepth = 64
 
+// qLimits are the largest number of items in a node before it's split, and
+// the depth where nodes are no longer split.
+type qLimits struct {
+	maxItems int
+	maxDepth int
+}
+
+// qLimitsFromOpts returns the limits of the options, where zero values use
+// the defaults.
+func qLimitsFromOpts(opts *IndexOptions) qLimits {
+	limits := qLimits{qMaxItems, qMaxDepth}
+	if opts != nil {
+		if opts.MaxNodeItems > 0 {
+			limits.maxItems = opts.MaxNodeItems
+		}
+		if opts.MaxDepth > 0 {
+			limits.maxDepth = opts.MaxDepth
+		}
+	}
+	return limits
+}
+
 type qNode struct {
 	split bool
 	items []int
 	quads [4]*qNode
 }
 
-func (n *qNode) insert(series *baseSeries, bounds, rect Rect, item, depth int) {
-	if depth == qMaxDepth {
+func (n *qNode) insert(
+	series *baseSeries, bounds, rect Rect, item, depth int, limits qLimits,
+) {
+	if depth >= limits.maxDepth {
 		// limit depth and insert now
 		n.items = append(n.items, item)
 	} else if n.split {
@@ -36,9 +60,9 @@ func (n *qNode) insert(series *baseSeries, bounds, rect Rect, item, depth int) {
 			if n.quads[q] == nil {
 				n.quads[q] = new(qNode)
 			}
-			n.quads[q].insert(series, qbounds, rect, item, depth+1)
+			n.quads[q].insert(series, qbounds, rect, item, depth+1, limits)
 		}
-	} else if len(n.items) == qMaxItems {
+	} else if len(n.items) >= limits.maxItems {
 		// split qnode, keep current items in place
 		var nitems []int
 		for i := 0; i < len(n.items); i++ {
@@ -52,12 +76,13 @@ func (n *qNode) insert(series *baseSeries, bounds, rect Rect, item, depth int) {
 				if n.quads[q] == nil {
 					n.quads[q] = new(qNode)
 				}
-				n.quads[q].insert(series, qbounds, irect, int(iitem), depth+1)
+				n.quads[q].insert(series, qbounds, irect, int(iitem), depth+1,
+					limits)
 			}
 		}
 		n.items = nitems
 		n.split = true
-		n.insert(series, bounds, rect, item, depth)
+		n.insert(series, bounds, rect, item, depth, limits)
 	} else {
 		n.items = append(n.items, item)
 	}
@@ -215,6 +240,227 @@ func qCompressSearch(
 	return true
 }
 
+// qCompressContained returns true if every segment in the compressed
+// quadtree is within the bounds of its node, which is needed for searches to
+// find all of the segments.
+func qCompressContained(
+	data []byte,
+	addr int,
+	series *baseSeries,
+	bounds Rect,
+) bool {
+	var nitems uint64
+	nitems, addr = readUvarint(data, addr)
+	var last uint64
+	for i := uint64(0); i < nitems; i++ {
+		var item uint64
+		item, addr = readUvarint(data, addr)
+		item += last
+		if !bounds.ContainsRect(series.SegmentAt(int(item)).Rect()) {
+			return false
+		}
+		last = item
+	}
+	if data[addr] == 1 {
+		addr++
+		for q := 0; q < 4; q++ {
+			var item uint64
+			item, addr = readUvarint(data, addr)
+			if item == 0 {
+				// empty quad
+				continue
+			}
+			qsize := item
+			if !qCompressContained(data, addr, series, quadBounds(bounds, q)) {
+				return false
+			}
+			addr += int(qsize)
+		}
+	}
+	return true
+}
+
+// qCompressWalk calls iter for each node in the compressed quadtree.
+func qCompressWalk(
+	data []byte,
+	addr int,
+	bounds Rect,
+	depth int,
+	iter func(bounds Rect, depth int, segmentCount int) bool,
+) bool {
+	var nitems uint64
+	nitems, addr = readUvarint(data, addr)
+	if !iter(bounds, depth, int(nitems)) {
+		return false
+	}
+	for i := uint64(0); i < nitems; i++ {
+		_, addr = readUvarint(data, addr)
+	}
+	if data[addr] == 1 {
+		addr++
+		for q := 0; q < 4; q++ {
+			var item uint64
+			item, addr = readUvarint(data, addr)
+			if item == 0 {
+				// empty quad
+				continue
+			}
+			qsize := item
+			if !qCompressWalk(data, addr, quadBounds(bounds, q), depth+1,
+				iter) {
+				return false
+			}
+			addr += int(qsize)
+		}
+	}
+	return true
+}
+
+// qCompressValid returns true if the node at addr fills data[addr:end]
+// exactly, and all of its items are below nsegs. The readers above trust the
+// layout, so untrusted data is checked with this before it's used.
+func qCompressValid(data []byte, addr, end, nsegs int) bool {
+	read := func() (uint64, bool) {
+		x, n := binary.Uvarint(data[addr:end])
+		addr += n
+		return x, n > 0
+	}
+	nitems, ok := read()
+	if !ok || nitems > uint64(end-addr) {
+		return false
+	}
+	var last uint64
+	for i := uint64(0); i < nitems; i++ {
+		item, ok := read()
+		if !ok || item >= uint64(nsegs)-last {
+			return false
+		}
+		last += item
+	}
+	if addr == end {
+		return false
+	}
+	split := data[addr]
+	addr++
+	if split == 0 {
+		return addr == end
+	}
+	if split != 1 {
+		return false
+	}
+	for q := 0; q < 4; q++ {
+		qsize, ok := read()
+		if !ok || qsize > uint64(end-addr) {
+			return false
+		}
+		if qsize > 0 {
+			if !qCompressValid(data, addr, addr+int(qsize), nsegs) {
+				return false
+			}
+			addr += int(qsize)
+		}
+	}
+	return addr == end
+}
+
+// qCompressCount returns the number of segments that intersect the rect.
+func qCompressCount(
+	data []byte,
+	addr int,
+	series *baseSeries,
+	bounds Rect,
+	rect Rect,
+) int {
+	var count int
+	var nitems uint64
+	nitems, addr = readUvarint(data, addr)
+	var last uint64
+	for i := uint64(0); i < nitems; i++ {
+		var item uint64
+		item, addr = readUvarint(data, addr)
+		item += last
+		if series.SegmentAt(int(item)).Rect().IntersectsRect(rect) {
+			count++
+		}
+		last = item
+	}
+	if data[addr] == 1 {
+		addr++
+		for q := 0; q < 4; q++ {
+			var item uint64
+			item, addr = readUvarint(data, addr)
+			if item == 0 {
+				// empty quad
+				continue
+			}
+			qsize := item
+			qbounds := quadBounds(bounds, q)
+			if qbounds.IntersectsRect(rect) {
+				count += qCompressCount(data, addr, series, qbounds, rect)
+			}
+			addr += int(qsize)
+		}
+	}
+	return count
+}
+
+// qCompressCountBatch counts the segments that intersect each of the query
+// rectangles using a single traversal of the compressed quadtree. The active
+// param holds the indexes of the rects that intersect the node bounds.
+func qCompressCountBatch(
+	data []byte,
+	addr int,
+	series *baseSeries,
+	bounds Rect,
+	rects []Rect,
+	active []int,
+	counts []int,
+) {
+	var nitems uint64
+	nitems, addr = readUvarint(data, addr)
+	var last uint64
+	for i := uint64(0); i < nitems; i++ {
+		var item uint64
+		item, addr = readUvarint(data, addr)
+		item += last
+		srect := series.SegmentAt(int(item)).Rect()
+		for _, j := range active {
+			if srect.IntersectsRect(rects[j]) {
+				counts[j]++
+			}
+		}
+		last = item
+	}
+	if data[addr] == 1 {
+		addr++
+		var qactive []int
+		for q := 0; q < 4; q++ {
+			var item uint64
+			item, addr = readUvarint(data, addr)
+			if item == 0 {
+				// empty quad
+				continue
+			}
+			qsize := item
+			qbounds := quadBounds(bounds, q)
+			qactive = qactive[:0]
+			for _, j := range active {
+				if qbounds.IntersectsRect(rects[j]) {
+					qactive = append(qactive, j)
+				}
+			}
+			if len(qactive) > 0 {
+				qCompressCountBatch(data, addr, series, qbounds, rects,
+					qactive, counts)
+			}
+			addr += int(qsize)
+		}
+	}
+}
+
+// qpool holds the queues for the nearest searches. A queue is taken by one
+// search and returned when it's done, so concurrent searches of the same
+// series never share a queue.
 var qpool = sync.Pool{
 	New: func() interface{} {
 		q := queue(make([]qnode, 0, 64))
@@ -298,3 +544,72 @@ outer_loop:
 		}
 	}
 }
+
+// qCompressNearest iterates over the segments in the compressed quadtree in
+// the order of nearest to farthest.
+func qCompressNearest(
+	data []byte, addr int, series *baseSeries, bounds Rect,
+	distToRect func(rect Rect) float64,
+	distToSegment func(seg Segment) float64,
+	iter func(seg Segment, idx int, dist float64) bool,
+) {
+	q := qpool.Get().(*queue)
+	*q = (*q)[:0]
+	defer func() { qpool.Put(q) }()
+	for {
+		var nitems uint64
+		nitems, addr = readUvarint(data, addr)
+		var last uint64
+		for i := uint64(0); i < nitems; i++ {
+			var item uint64
+			item, addr = readUvarint(data, addr)
+			item += last
+			seg := series.SegmentAt(int(item))
+			q.push(qnode{
+				kind: qseg,
+				dist: distToSegment(seg),
+				a:    seg.A,
+				b:    seg.B,
+				pos:  int(item),
+			})
+			last = item
+		}
+		split := data[addr] == 1
+		addr++
+		if split {
+			for i := 0; i < 4; i++ {
+				var item uint64
+				item, addr = readUvarint(data, addr)
+				if item == 0 {
+					// empty quad
+					continue
+				}
+				qsize := item
+				qbounds := quadBounds(bounds, i)
+				q.push(qnode{
+					kind: qrect,
+					dist: distToRect(qbounds),
+					a:    qbounds.Min,
+					b:    qbounds.Max,
+					pos:  int(addr),
+				})
+				addr += int(qsize)
+			}
+		}
+		for {
+			node, ok := q.pop()
+			if !ok {
+				return
+			}
+			if node.kind == qseg {
+				if !iter(Segment{A: node.a, B: node.b}, node.pos, node.dist) {
+					return
+				}
+				continue
+			}
+			addr = node.pos
+			bounds = Rect{Min: node.a, Max: node.b}
+			break
+		}
+	}
+}
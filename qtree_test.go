@@ -19,7 +19,8 @@ func TestQTreeVarious(t *testing.T) {
 	t.Run("max-depth", func(t *testing.T) {
 		var n qNode
 		for i := 0; i < 100; i++ {
-			n.insert(nil, Rect{}, Rect{}, 0, qMaxDepth)
+			n.insert(nil, Rect{}, Rect{}, 0, qMaxDepth,
+				qLimitsFromOpts(nil))
 		}
 		expect(t, len(n.items) == 100)
 	})
@@ -54,6 +55,48 @@ func TestQTreeSanity(t *testing.T) {
 
 }
 
+func TestQTreeLimits(t *testing.T) {
+	stats := func(series Series) (nodes, maxItems, maxDepth int) {
+		WalkIndex(series, func(bounds Rect, depth, segmentCount int) bool {
+			nodes++
+			if segmentCount > maxItems {
+				maxItems = segmentCount
+			}
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+			return true
+		})
+		return nodes, maxItems, maxDepth
+	}
+	def := NewLine(AZ, DefaultIndexOptions)
+	expect(t, qSane(&def.baseSeries) == nil)
+	defNodes, _, _ := stats(def)
+	// smaller nodes split more
+	small := NewLine(AZ, &IndexOptions{
+		Kind: QuadTree, MinPoints: 64, MaxNodeItems: 4,
+	})
+	expect(t, qSane(&small.baseSeries) == nil)
+	smallNodes, _, _ := stats(small)
+	expect(t, smallNodes > defNodes)
+	// shallow trees hold more in each node
+	shallow := NewLine(AZ, &IndexOptions{
+		Kind: QuadTree, MinPoints: 64, MaxNodeItems: 4, MaxDepth: 2,
+	})
+	expect(t, qSane(&shallow.baseSeries) == nil)
+	_, maxItems, maxDepth := stats(shallow)
+	expect(t, maxDepth == 2 && maxItems > 4)
+	// the mutable series uses the same options
+	ms := NewMutableSeries(AZ[:100], &IndexOptions{
+		Kind: QuadTree, MinPoints: 64, MaxNodeItems: 4, MaxDepth: 3,
+	})
+	for _, point := range AZ[100:] {
+		ms.Append(point)
+	}
+	_, _, maxDepth = stats(ms)
+	expect(t, maxDepth == 3)
+}
+
 // qSane performs a sanity check on the quadtree.
 // The check verifies:
 // - All segments exist in the tree.
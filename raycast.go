@@ -4,15 +4,31 @@
 
 package geometry
 
-import "math"
+import (
+	"encoding/binary"
+	"math"
+)
 
 // RaycastResult holds the results of the Raycast operation
 type RaycastResult struct {
-	In bool // point on the left
-	On bool // point is directly on top of
+	In bool // ray from the point crosses the segment
+	On bool // point is directly on top of the segment
 }
 
-// Raycast performs the raycast operation
+// Raycast performs the raycast operation, which casts a ray from the point
+// towards positive infinity along the X axis.
+//
+// On is true when the point lies on the segment, including its endpoints, in
+// which case In is always false.
+// In is true when the ray crosses the segment. A point with the same Y as one
+// of the endpoints is treated as being infinitesimally above it, so a ray
+// through a vertex that is shared by two segments is only counted once when
+// the segments are on opposite sides of the ray, and zero or two times when
+// they are on the same side. Horizontal segments are never crossed.
+//
+// Counting the segments of a ring where In is true, the point is inside of
+// the ring when the count is odd, which makes it possible to build custom
+// point-in-polygon tests.
 func (seg Segment) Raycast(point Point) RaycastResult {
 
 	p, a, b := point, seg.A, seg.B
@@ -101,3 +117,94 @@ func (seg Segment) Raycast(point Point) RaycastResult {
 	}
 	return RaycastResult{false, false}
 }
+
+// RayFirstHit returns the first point where a ray from the origin hits the
+// series, along with the index of the segment that is hit. The ray goes
+// towards the direction, which does not need to be normalized. When the
+// series has an index, the quadtree is walked in the order that the ray
+// passes through it, which stops as soon as the nearest hit is known.
+// Returns false when the ray does not hit the series or when the direction
+// is zero.
+func RayFirstHit(series Series, origin, direction Point) (hit Point,
+	segIdx int, ok bool) {
+	if direction.X == 0 && direction.Y == 0 {
+		return Point{}, -1, false
+	}
+	hitParam := func(seg Segment) float64 {
+		return rayHitSegment(origin, direction, seg)
+	}
+	best, segIdx := math.Inf(+1), -1
+	base := seriesBase(series)
+	if base == nil || len(base.index) == 0 {
+		series.Search(series.Rect(), func(seg Segment, idx int) bool {
+			if t := hitParam(seg); t < best {
+				best, segIdx = t, idx
+			}
+			return true
+		})
+	} else {
+		data := base.index
+		n := binary.LittleEndian.Uint32(data[1:])
+		data = data[:n:n]
+		qCompressNearest(data, 5, base, base.rect,
+			func(rect Rect) float64 {
+				return rayHitRect(origin, direction, rect)
+			},
+			hitParam,
+			func(seg Segment, idx int, t float64) bool {
+				if !math.IsInf(t, +1) {
+					best, segIdx = t, idx
+				}
+				return false
+			},
+		)
+	}
+	if segIdx == -1 {
+		return Point{}, -1, false
+	}
+	hit = Point{origin.X + direction.X*best, origin.Y + direction.Y*best}
+	return hit, segIdx, true
+}
+
+// rayHitRect returns the parameter along the ray where it first touches the
+// rectangle, which is zero when the origin is inside of the rectangle.
+// Returns +Inf when the ray misses the rectangle.
+func rayHitRect(origin, direction Point, rect Rect) float64 {
+	t0, _, ok := rectClipLine(rect, origin, direction, 0, math.Inf(+1))
+	if !ok {
+		return math.Inf(+1)
+	}
+	return t0
+}
+
+// rayHitSegment returns the parameter along the ray where it first touches
+// the segment. Returns +Inf when the ray misses the segment.
+func rayHitSegment(origin, direction Point, seg Segment) float64 {
+	rx, ry := direction.X, direction.Y
+	sx, sy := seg.B.X-seg.A.X, seg.B.Y-seg.A.Y
+	qx, qy := seg.A.X-origin.X, seg.A.Y-origin.Y
+	denom := rx*sy - ry*sx
+	if denom != 0 {
+		t := (qx*sy - qy*sx) / denom
+		u := (qx*ry - qy*rx) / denom
+		if t < 0 || u < 0 || u > 1 {
+			return math.Inf(+1)
+		}
+		return t
+	}
+	if qx*ry-qy*rx != 0 {
+		// parallel
+		return math.Inf(+1)
+	}
+	// collinear, so use the nearest part of the segment
+	rr := rx*rx + ry*ry
+	ta := (qx*rx + qy*ry) / rr
+	tb := ((seg.B.X-origin.X)*rx + (seg.B.Y-origin.Y)*ry) / rr
+	if ta > tb {
+		ta, tb = tb, ta
+	}
+	if tb < 0 {
+		return math.Inf(+1)
+	}
+	return math.Max(ta, 0)
+}
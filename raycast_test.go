@@ -6,6 +6,7 @@ package geometry
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"testing"
 )
@@ -156,3 +157,101 @@ func TestSegmentRaycast(t *testing.T) {
 		t.Fatalf("\n%s", ms)
 	}
 }
+
+func TestSegmentRaycastClassification(t *testing.T) {
+	seg := S(2, 0, 2, 10)
+	// on a vertex
+	expect(t, seg.Raycast(P(2, 0)) == RaycastResult{false, true})
+	expect(t, seg.Raycast(P(2, 10)) == RaycastResult{false, true})
+	// on the edge
+	expect(t, seg.Raycast(P(2, 5)) == RaycastResult{false, true})
+	expect(t, S(0, 0, 10, 5).Raycast(P(4, 2)) == RaycastResult{false, true})
+	// strictly inside of the ray path
+	expect(t, seg.Raycast(P(0, 5)) == RaycastResult{true, false})
+	expect(t, S(0, 0, 10, 5).Raycast(P(0, 2)) == RaycastResult{true, false})
+	// beside, but the ray goes away from the segment
+	expect(t, seg.Raycast(P(3, 5)) == RaycastResult{false, false})
+	// level with a vertex counts as being just above it
+	expect(t, seg.Raycast(P(0, 0)) == RaycastResult{true, false})
+	expect(t, seg.Raycast(P(0, 10)) == RaycastResult{false, false})
+	// horizontal segments are never crossed
+	expect(t, S(2, 5, 8, 5).Raycast(P(0, 5)) == RaycastResult{false, false})
+
+	// an even-odd point-in-polygon test that counts the crossings
+	evenOdd := func(ring Ring, point Point) bool {
+		var in bool
+		for i := 0; i < ring.NumSegments(); i++ {
+			res := ring.SegmentAt(i).Raycast(point)
+			if res.On {
+				return true
+			}
+			if res.In {
+				in = !in
+			}
+		}
+		return in
+	}
+	for _, shape := range [][]Point{octagon, concave1, concave2, concave3,
+		concave4} {
+		poly := NewPoly(shape, nil, nil)
+		for x := -1.0; x <= 11; x += 0.5 {
+			for y := -1.0; y <= 11; y += 0.5 {
+				expect(t, evenOdd(poly.Exterior, P(x, y)) ==
+					poly.ContainsPoint(P(x, y)))
+			}
+		}
+	}
+}
+
+func TestRayFirstHit(t *testing.T) {
+	// a box with an inner wall that is parallel to the right wall
+	var points []Point
+	for i := 0; i <= 100; i++ {
+		points = append(points, P(float64(i)/10, 0))
+	}
+	points = append(points, P(10, 10), P(6, 10), P(6, 2), P(5, 2), P(5, 10),
+		P(0, 10), P(0, 0))
+	testDualRingX(t, points, func(t *testing.T, ring Ring) {
+		hit, idx, ok := RayFirstHit(ring, P(1, 5), P(1, 0))
+		expect(t, ok && hit == P(5, 5))
+		expect(t, ring.SegmentAt(idx) == S(5, 2, 5, 10))
+		// not normalized
+		hit, idx, ok = RayFirstHit(ring, P(1, 5), P(20, 0))
+		expect(t, ok && hit == P(5, 5))
+		expect(t, ring.SegmentAt(idx) == S(5, 2, 5, 10))
+		// from the other side
+		hit, idx, ok = RayFirstHit(ring, P(9, 5), P(-1, 0))
+		expect(t, ok && hit == P(6, 5))
+		expect(t, ring.SegmentAt(idx) == S(6, 10, 6, 2))
+		// below the inner wall
+		hit, idx, ok = RayFirstHit(ring, P(1, 1), P(1, 0))
+		expect(t, ok && hit == P(10, 1))
+		expect(t, ring.SegmentAt(idx) == S(10, 0, 10, 10))
+		// diagonal
+		hit, _, ok = RayFirstHit(ring, P(1, 1), P(1, 1))
+		expect(t, ok && hit == P(5, 5))
+		// from outside, and away from the box
+		hit, _, ok = RayFirstHit(ring, P(-5, 5), P(1, 0))
+		expect(t, ok && hit == P(0, 5))
+		_, _, ok = RayFirstHit(ring, P(-5, 5), P(-1, 0))
+		expect(t, !ok)
+		_, idx, ok = RayFirstHit(ring, P(1, 5), P(0, 0))
+		expect(t, !ok && idx == -1)
+	})
+	// collinear with a segment
+	hit, idx, ok := RayFirstHit(L(P(2, 0), P(4, 0)), P(0, 0), P(1, 0))
+	expect(t, ok && hit == P(2, 0) && idx == 0)
+	hit, _, ok = RayFirstHit(L(P(2, 0), P(4, 0)), P(3, 0), P(1, 0))
+	expect(t, ok && hit == P(3, 0))
+	// an indexed line is walked using its index
+	line := NewLine(AZ, DefaultIndexOptions)
+	plain := NewLine(AZ, NoIndexing)
+	expect(t, seriesBase(line) != nil && len(line.Index()) > 0)
+	center := line.Rect().Center()
+	for i := 0; i < 64; i++ {
+		sin, cos := math.Sincos(float64(i) * math.Pi / 32)
+		hit, idx, ok := RayFirstHit(line, center, P(cos, sin))
+		expHit, expIdx, expOK := RayFirstHit(plain, center, P(cos, sin))
+		expect(t, ok == expOK && hit == expHit && idx == expIdx)
+	}
+}
@@ -4,6 +4,8 @@
 
 package geometry
 
+import "math"
+
 type Rect struct {
 	Min, Max Point
 }
@@ -76,6 +78,45 @@ func (rect Rect) East() Segment  { return Segment{rect.SE(), rect.NE()} }
 func (rect Rect) North() Segment { return Segment{rect.NE(), rect.NW()} }
 func (rect Rect) West() Segment  { return Segment{rect.NW(), rect.SW()} }
 
+// Corners returns the four corners of the rectangle in counter-clockwise
+// order, starting from the minimum point: SW, SE, NE, NW. This is the same
+// order as PointAt.
+func (rect Rect) Corners() [4]Point {
+	return [4]Point{rect.SW(), rect.SE(), rect.NE(), rect.NW()}
+}
+
+// Edges returns the four edges of the rectangle in counter-clockwise order:
+// South, East, North, West. Each edge goes from the corner at the same index
+// in Corners to the next corner, so the edges make a closed loop.
+func (rect Rect) Edges() [4]Segment {
+	return [4]Segment{rect.South(), rect.East(), rect.North(), rect.West()}
+}
+
+// Transform returns the bounding rectangle of the four corners of the
+// rectangle after the affine transformation is applied to them. For a
+// rotation or shear this is larger than the rectangle itself.
+func (rect Rect) Transform(m Affine) Rect {
+	var bounds Rect
+	for i, point := range rect.Corners() {
+		point = m.Apply(point)
+		if i == 0 {
+			bounds = Rect{point, point}
+		} else {
+			bounds = bounds.Union(Rect{point, point})
+		}
+	}
+	return bounds
+}
+
+// RotatedBounds returns the bounding rectangle of the rectangle after it is
+// rotated counter-clockwise around origin by radians. This is useful for
+// finding the bounds of a rotated geometry without rotating its points.
+func (rect Rect) RotatedBounds(origin Point, radians float64) Rect {
+	return rect.Transform(Translate(-origin.X, -origin.Y).
+		Multiply(Rotation(radians)).
+		Multiply(Translate(origin.X, origin.Y)))
+}
+
 func (rect Rect) Closed() bool {
 	return true
 }
@@ -109,6 +150,11 @@ func (rect Rect) Search(target Rect, iter func(seg Segment, idx int) bool) {
 	}
 }
 
+// WithinRect returns true if the rect is fully inside of the other rect.
+func (rect Rect) WithinRect(other Rect) bool {
+	return other.ContainsRect(rect)
+}
+
 func (rect Rect) Empty() bool {
 	return false
 }
@@ -140,6 +186,56 @@ func (rect Rect) ContainsRect(other Rect) bool {
 	return true
 }
 
+// ContainsSegment returns true if both segment points are inside the rect.
+func (rect Rect) ContainsSegment(seg Segment) bool {
+	return rect.ContainsPoint(seg.A) && rect.ContainsPoint(seg.B)
+}
+
+// IntersectsSegment returns true if any part of the segment is inside the
+// rect. Unlike testing the segment's bounding rect, a diagonal segment that
+// passes by a corner of the rect does not intersect.
+func (rect Rect) IntersectsSegment(seg Segment) bool {
+	_, _, ok := rectClipSegment(rect, seg)
+	return ok
+}
+
+// rectClipSegment uses slab clipping to find the portion of the segment that
+// is inside the rect. Returns the parameters, in the range [0,1], of the
+// entry and exit points, and false if the segment is fully outside.
+func rectClipSegment(rect Rect, seg Segment) (t0, t1 float64, ok bool) {
+	return rectClipLine(rect, seg.A,
+		Point{seg.B.X - seg.A.X, seg.B.Y - seg.A.Y}, 0, 1)
+}
+
+// rectClipLine uses slab clipping to find the portion of the line
+// origin+t*dir, for t from t0 to t1, that is inside the rect. Returns the
+// parameters of the entry and exit points, and false if the line is fully
+// outside. A t1 of +Inf clips a ray.
+func rectClipLine(rect Rect, origin, dir Point, t0, t1 float64) (float64,
+	float64, bool) {
+	slab := func(a, d, min, max float64) bool {
+		if d == 0 {
+			return a >= min && a <= max
+		}
+		ta, tb := (min-a)/d, (max-a)/d
+		if ta > tb {
+			ta, tb = tb, ta
+		}
+		if ta > t0 {
+			t0 = ta
+		}
+		if tb < t1 {
+			t1 = tb
+		}
+		return t0 <= t1
+	}
+	if !slab(origin.X, dir.X, rect.Min.X, rect.Max.X) ||
+		!slab(origin.Y, dir.Y, rect.Min.Y, rect.Max.Y) {
+		return 0, 0, false
+	}
+	return t0, t1, true
+}
+
 func (rect Rect) IntersectsRect(other Rect) bool {
 	if rect.Min.Y > other.Max.Y || rect.Max.Y < other.Min.Y {
 		return false
@@ -150,6 +246,22 @@ func (rect Rect) IntersectsRect(other Rect) bool {
 	return true
 }
 
+// IntersectionRect returns the rectangle where two rectangles overlap, and
+// false if they do not intersect. Rectangles that only touch at an edge or a
+// corner do intersect, and the returned rectangle is degenerate with a zero
+// width or height.
+func (rect Rect) IntersectionRect(other Rect) (Rect, bool) {
+	if !rect.IntersectsRect(other) {
+		return Rect{}, false
+	}
+	return Rect{
+		Min: Point{math.Max(rect.Min.X, other.Min.X),
+			math.Max(rect.Min.Y, other.Min.Y)},
+		Max: Point{math.Min(rect.Max.X, other.Max.X),
+			math.Min(rect.Max.Y, other.Max.Y)},
+	}, true
+}
+
 func (rect Rect) ContainsLine(line *Line) bool {
 	if line == nil {
 		return false
@@ -178,6 +290,66 @@ func (rect Rect) IntersectsPoly(poly *Poly) bool {
 	return poly.IntersectsRect(rect)
 }
 
+// SplitGrid divides the rect into a grid of cols*rows equally sized rects.
+// The rects are returned in row-major order, starting with the row at the
+// minimum Y and the column at the minimum X. Neighboring rects share their
+// edges exactly. Returns the rect itself when cols or rows is less than one.
+func (rect Rect) SplitGrid(cols, rows int) []Rect {
+	if cols < 1 || rows < 1 {
+		return []Rect{rect}
+	}
+	edge := func(min, max float64, i, n int) float64 {
+		if i == n {
+			return max
+		}
+		return min + (max-min)*float64(i)/float64(n)
+	}
+	rects := make([]Rect, 0, cols*rows)
+	for row := 0; row < rows; row++ {
+		minY := edge(rect.Min.Y, rect.Max.Y, row, rows)
+		maxY := edge(rect.Min.Y, rect.Max.Y, row+1, rows)
+		for col := 0; col < cols; col++ {
+			rects = append(rects, Rect{
+				Min: Point{edge(rect.Min.X, rect.Max.X, col, cols), minY},
+				Max: Point{edge(rect.Min.X, rect.Max.X, col+1, cols), maxY},
+			})
+		}
+	}
+	return rects
+}
+
+// DistanceToPoint returns the distance from the point to the nearest point
+// in the rectangle, or zero if the point is inside. This is the natural
+// distToRect function for DistanceToSeries.
+func (rect Rect) DistanceToPoint(point Point) float64 {
+	return math.Sqrt(rectDistSqToPoint(rect, point))
+}
+
+// rectDistSqToPoint returns the squared distance from the point to the
+// nearest point in the rectangle.
+func rectDistSqToPoint(rect Rect, point Point) float64 {
+	var dx, dy float64
+	if point.X < rect.Min.X {
+		dx = rect.Min.X - point.X
+	} else if point.X > rect.Max.X {
+		dx = point.X - rect.Max.X
+	}
+	if point.Y < rect.Min.Y {
+		dy = rect.Min.Y - point.Y
+	} else if point.Y > rect.Max.Y {
+		dy = point.Y - rect.Max.Y
+	}
+	return dx*dx + dy*dy
+}
+
+// DistanceToRect returns the distance between the nearest points of the
+// rectangle and the other rectangle, or zero if they intersect.
+func (rect Rect) DistanceToRect(other Rect) float64 {
+	dx := math.Max(0, math.Max(rect.Min.X-other.Max.X, other.Min.X-rect.Max.X))
+	dy := math.Max(0, math.Max(rect.Min.Y-other.Max.Y, other.Min.Y-rect.Max.Y))
+	return math.Sqrt(dx*dx + dy*dy)
+}
+
 func (rect Rect) Union(other Rect) Rect {
 	if other.Min.X < rect.Min.X {
 		rect.Min.X = other.Min.X
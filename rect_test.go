@@ -5,6 +5,7 @@
 package geometry
 
 import (
+	"math"
 	"testing"
 )
 
@@ -190,3 +191,181 @@ func TestRectUnion(t *testing.T) {
 	b := R(9, 9, 21, 21)
 	expect(t, a.Union(b) == b)
 }
+
+func TestRectContainsSegment(t *testing.T) {
+	expect(t, R(0, 0, 10, 10).ContainsSegment(S(1, 1, 9, 9)))
+	expect(t, R(0, 0, 10, 10).ContainsSegment(S(0, 0, 10, 10)))
+	expect(t, !R(0, 0, 10, 10).ContainsSegment(S(1, 1, 11, 9)))
+	expect(t, !R(0, 0, 10, 10).ContainsSegment(S(-1, -1, -2, -2)))
+}
+
+func TestRectDistanceToPoint(t *testing.T) {
+	// the distance to the nearest of the clamped coordinates
+	want := func(p Point, r Rect) float64 {
+		dx := math.Max(r.Min.X-p.X, math.Max(0, p.X-r.Max.X))
+		dy := math.Max(r.Min.Y-p.Y, math.Max(0, p.Y-r.Max.Y))
+		return math.Sqrt(dx*dx + dy*dy)
+	}
+	rect := R(0, 0, 10, 10)
+	for x := -5.0; x <= 15; x += 2.5 {
+		for y := -5.0; y <= 15; y += 2.5 {
+			p := P(x, y)
+			expect(t, rect.DistanceToPoint(p) == want(p, rect))
+		}
+	}
+	expect(t, rect.DistanceToPoint(P(5, 5)) == 0)
+	expect(t, rect.DistanceToPoint(P(10, 0)) == 0)
+	expect(t, rect.DistanceToPoint(P(13, 14)) == 5)
+	expect(t, rect.DistanceToPoint(P(5, -2)) == 2)
+}
+
+func TestRectDistanceToRect(t *testing.T) {
+	rect := R(0, 0, 10, 10)
+	for x := -5.0; x <= 15; x += 2.5 {
+		for y := -5.0; y <= 15; y += 2.5 {
+			// a point-sized rect is the same as the point
+			p := P(x, y)
+			expect(t, rect.DistanceToRect(p.Rect()) == rect.DistanceToPoint(p))
+			expect(t, p.Rect().DistanceToRect(rect) == rect.DistanceToPoint(p))
+		}
+	}
+	// intersecting and touching
+	expect(t, rect.DistanceToRect(R(5, 5, 15, 15)) == 0)
+	expect(t, rect.DistanceToRect(R(2, 2, 3, 3)) == 0)
+	expect(t, rect.DistanceToRect(R(10, 10, 20, 20)) == 0)
+	// apart on one axis
+	expect(t, rect.DistanceToRect(R(12, 2, 20, 8)) == 2)
+	expect(t, rect.DistanceToRect(R(-20, -5, 20, -3)) == 3)
+	// apart on both axes
+	expect(t, rect.DistanceToRect(R(13, 14, 20, 20)) == 5)
+	expect(t, R(13, 14, 20, 20).DistanceToRect(rect) == 5)
+}
+
+func TestRectIntersectsSegment(t *testing.T) {
+	rect := R(0, 0, 10, 10)
+	expect(t, rect.IntersectsSegment(S(1, 1, 9, 9)))
+	expect(t, rect.IntersectsSegment(S(-5, 5, 15, 5)))
+	expect(t, rect.IntersectsSegment(S(5, -5, 5, 15)))
+	expect(t, rect.IntersectsSegment(S(-5, -5, 15, 15)))
+	expect(t, rect.IntersectsSegment(S(5, 5, 20, 20)))
+	expect(t, rect.IntersectsSegment(S(-10, 20, 0, 10)))
+	expect(t, rect.IntersectsSegment(S(10, 0, 20, 0)))
+	expect(t, rect.IntersectsSegment(S(5, 5, 5, 5)))
+	expect(t, !rect.IntersectsSegment(S(20, 20, 20, 20)))
+	expect(t, !rect.IntersectsSegment(S(-5, 11, 15, 11)))
+	expect(t, !rect.IntersectsSegment(S(11, -5, 11, 15)))
+	// the bounding rects overlap, but the segment passes by the corner
+	seg := S(9, 13, 13, 9)
+	expect(t, seg.Rect().IntersectsRect(rect))
+	expect(t, !rect.IntersectsSegment(seg))
+	seg = S(-3, 1, 1, -3)
+	expect(t, seg.Rect().IntersectsRect(rect))
+	expect(t, !rect.IntersectsSegment(seg))
+	seg = S(-1, 1, 1, -1)
+	expect(t, rect.IntersectsSegment(seg))
+}
+
+func TestRectSplitGrid(t *testing.T) {
+	rect := R(-1.3, 2.7, 10.1, 20.9)
+	for _, dims := range [][2]int{{1, 1}, {2, 2}, {3, 7}, {10, 1}, {13, 17}} {
+		cols, rows := dims[0], dims[1]
+		rects := rect.SplitGrid(cols, rows)
+		expect(t, len(rects) == cols*rows)
+		union := rects[0]
+		var area float64
+		for i, a := range rects {
+			union = union.Union(a)
+			area += a.Area()
+			expect(t, rect.ContainsRect(a))
+			for j, b := range rects {
+				if i != j {
+					// neighbors may only share edges
+					overlapX := math.Min(a.Max.X, b.Max.X) - math.Max(a.Min.X, b.Min.X)
+					overlapY := math.Min(a.Max.Y, b.Max.Y) - math.Max(a.Min.Y, b.Min.Y)
+					expect(t, overlapX <= 0 || overlapY <= 0)
+				}
+			}
+		}
+		expect(t, union == rect)
+		expect(t, math.Abs(area-rect.Area()) < 1e-9)
+	}
+	rects := R(0, 0, 4, 2).SplitGrid(2, 2)
+	expect(t, rects[0] == R(0, 0, 2, 1))
+	expect(t, rects[1] == R(2, 0, 4, 1))
+	expect(t, rects[2] == R(0, 1, 2, 2))
+	expect(t, rects[3] == R(2, 1, 4, 2))
+	expect(t, len(rect.SplitGrid(0, 5)) == 1 && rect.SplitGrid(0, 5)[0] == rect)
+	expect(t, len(rect.SplitGrid(5, -1)) == 1)
+}
+
+func TestRectIntersectionRect(t *testing.T) {
+	rect := R(0, 0, 10, 10)
+	// fully contained
+	r, ok := rect.IntersectionRect(R(2, 3, 4, 5))
+	expect(t, ok && r == R(2, 3, 4, 5))
+	r, ok = R(2, 3, 4, 5).IntersectionRect(rect)
+	expect(t, ok && r == R(2, 3, 4, 5))
+	// partial overlap
+	r, ok = rect.IntersectionRect(R(5, -5, 15, 5))
+	expect(t, ok && r == R(5, 0, 10, 5))
+	// edge touching
+	r, ok = rect.IntersectionRect(R(10, 2, 20, 8))
+	expect(t, ok && r == R(10, 2, 10, 8) && r.Area() == 0)
+	// corner touching
+	r, ok = rect.IntersectionRect(R(10, 10, 20, 20))
+	expect(t, ok && r == R(10, 10, 10, 10))
+	// disjoint
+	r, ok = rect.IntersectionRect(R(11, 0, 20, 10))
+	expect(t, !ok && r == Rect{})
+	_, ok = rect.IntersectionRect(R(0, -5, 10, -1))
+	expect(t, !ok)
+}
+
+func TestRectCornersEdges(t *testing.T) {
+	rect := R(1, 2, 4, 6)
+	corners := rect.Corners()
+	edges := rect.Edges()
+	expect(t, corners == [4]Point{{1, 2}, {4, 2}, {4, 6}, {1, 6}})
+	for i := 0; i < 4; i++ {
+		expect(t, rect.ContainsPoint(corners[i]))
+		expect(t, corners[i] == rect.PointAt(i))
+		expect(t, edges[i].A == corners[i])
+		expect(t, edges[i].B == corners[(i+1)%4])
+		expect(t, edges[i].B == edges[(i+1)%4].A)
+		expect(t, edges[i] == rect.SegmentAt(i))
+	}
+	expect(t, signedArea(corners[:]) > 0)
+}
+
+func TestRectRotatedBounds(t *testing.T) {
+	// a square rotated by 45 degrees around its center grows by √2
+	rect := R(0, 0, 10, 10)
+	bounds := rect.RotatedBounds(P(5, 5), math.Pi/4)
+	half := 5 * math.Sqrt2
+	expect(t, pointsNear(bounds.Min, P(5-half, 5-half), 1e-12))
+	expect(t, pointsNear(bounds.Max, P(5+half, 5+half), 1e-12))
+	expect(t, math.Abs(bounds.Max.X-bounds.Min.X-10*math.Sqrt2) < 1e-12)
+	// a quarter turn around a corner
+	bounds = rect.RotatedBounds(P(0, 0), math.Pi/2)
+	expect(t, pointsNear(bounds.Min, P(-10, 0), 1e-12))
+	expect(t, pointsNear(bounds.Max, P(0, 10), 1e-12))
+	// no rotation
+	expect(t, rect.RotatedBounds(P(3, 4), 0) == rect)
+	// same as rotating the corners as a series
+	series := makeSeries(rect.RawPoints(), true, true, nil)
+	for _, rad := range []float64{0.3, 1, 2, math.Pi} {
+		a := rect.RotatedBounds(P(2, -7), rad)
+		b := series.RotatedBounds(P(2, -7), rad, true)
+		expect(t, pointsNear(a.Min, b.Min, 1e-12))
+		expect(t, pointsNear(a.Max, b.Max, 1e-12))
+	}
+}
+
+func TestRectTransform(t *testing.T) {
+	rect := R(1, 2, 3, 5)
+	expect(t, rect.Transform(Identity) == rect)
+	expect(t, rect.Transform(Translate(10, -2)) == rect.Move(10, -2))
+	expect(t, rect.Transform(Scaling(2, 3)) == R(2, 6, 6, 15))
+	// a mirror keeps the rectangle normalized
+	expect(t, rect.Transform(Scaling(-1, -1)) == R(-3, -5, -1, -2))
+}
@@ -10,8 +10,19 @@ import (
 
 const complexRingMinPoints = 16
 
+// Ring is a closed series of points, such as the exterior or a hole of a
+// Poly. It's the same as a Series, but the last point connects back to the
+// first point, whether or not the first point is repeated at the end. A Line
+// is the open counterpart, where the last point is not connected to the
+// first.
 type Ring = Series
 
+// NewRing creates a new Ring. The points are copied, and a segment index is
+// built as described by the options.
+func NewRing(points []Point, opts *IndexOptions) Ring {
+	return newRing(points, opts)
+}
+
 func newRing(points []Point, opts *IndexOptions) Ring {
 	series := makeSeries(points, true, true, opts)
 	return &series
@@ -78,6 +89,52 @@ func ringContainsPointGeneric(rect Rect, ring Ring, point Point, allowOnEdge boo
 	return in, idx
 }
 
+// signedArea returns the signed area of a ring using the shoelace formula.
+// The area is positive when the points are counter-clockwise. The ring may
+// or may not repeat the first point at the end.
+func signedArea(points []Point) float64 {
+	if len(points) < 3 {
+		return 0
+	}
+	var area float64
+	for i := 0; i < len(points); i++ {
+		a := points[i]
+		b := points[(i+1)%len(points)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+	return area / 2
+}
+
+// ringArea returns the unsigned area of the ring.
+func ringArea(ring Ring) float64 {
+	return math.Abs(signedArea(ring.RawPoints()))
+}
+
+// ringWindingNumber returns the nonzero winding number of the ring around the
+// point, and whether the point is directly on the edge of the ring.
+func ringWindingNumber(ring Ring, point Point) (winding int, onEdge bool) {
+	rect := Rect{point, Point{math.Inf(+1), point.Y}}
+	ring.Search(rect, func(seg Segment, index int) bool {
+		if seg.Raycast(point).On {
+			onEdge = true
+			return false
+		}
+		a, b := seg.A, seg.B
+		isLeft := (b.X-a.X)*(point.Y-a.Y) - (point.X-a.X)*(b.Y-a.Y)
+		if a.Y <= point.Y {
+			if b.Y > point.Y && isLeft > 0 {
+				// upward crossing
+				winding++
+			}
+		} else if b.Y <= point.Y && isLeft < 0 {
+			// downward crossing
+			winding--
+		}
+		return true
+	})
+	return winding, onEdge
+}
+
 func ringIntersectsPoint(ring Ring, point Point, allowOnEdge bool) ringResult {
 	return ringContainsPoint(ring, point, allowOnEdge)
 }
@@ -1431,3 +1431,39 @@ func TestIssue14(t *testing.T) {
 		}
 	})
 }
+
+func TestNewRing(t *testing.T) {
+	points := []Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	for _, opts := range []*IndexOptions{
+		nil, NoIndexing, {Kind: QuadTree, MinPoints: 1},
+	} {
+		ring := NewRing(points, opts)
+		expect(t, ring.Closed())
+		expect(t, ring.NumPoints() == 4)
+		expect(t, ring.NumSegments() == 4)
+		// the closing segment is searchable
+		var found bool
+		ring.Search(R(-1, 4, 1, 6), func(seg Segment, idx int) bool {
+			found = found || (idx == 3 && seg == S(0, 10, 0, 0))
+			return true
+		})
+		expect(t, found)
+		expect(t, !ring.Clockwise())
+	}
+	// the points are copied
+	ring := NewRing(points, nil)
+	points[0] = P(5, 5)
+	expect(t, ring.PointAt(0) == P(0, 0))
+	// a line with the same points is open
+	line := NewLine(points, nil)
+	expect(t, !line.Closed() && line.NumSegments() == 3)
+}
+
+func TestSignedArea(t *testing.T) {
+	square := []Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	expect(t, signedArea(square) == 100)
+	expect(t, signedArea(square[:4]) == 100)
+	expect(t, signedArea([]Point{{0, 0}, {0, 10}, {10, 10}, {10, 0}}) == -100)
+	expect(t, signedArea(octagon) == 82)
+	expect(t, signedArea(square[:2]) == 0)
+}
@@ -4,6 +4,8 @@
 
 package geometry
 
+import "math"
+
 // Segment is a two point line
 type Segment struct {
 	A, B Point
@@ -31,6 +33,120 @@ func (seg Segment) Rect() Rect {
 	return rect
 }
 
+// ClosestParameter returns the parameter t such that A+t*(B-A) is the foot
+// of the perpendicular from the point to the infinite line through the
+// segment. The result is not clamped, so it's less than zero or greater than
+// one for points beyond A or B. Returns zero for a zero-length segment.
+func (seg Segment) ClosestParameter(point Point) float64 {
+	dx, dy := seg.B.X-seg.A.X, seg.B.Y-seg.A.Y
+	l2 := dx*dx + dy*dy
+	if l2 == 0 {
+		return 0
+	}
+	return ((point.X-seg.A.X)*dx + (point.Y-seg.A.Y)*dy) / l2
+}
+
+// ClosestPoint returns the point on the segment that is nearest to the
+// provided point. For a point that is beyond either end of the segment, the
+// nearest endpoint is returned.
+func (seg Segment) ClosestPoint(point Point) Point {
+	t := seg.ClosestParameter(point)
+	if t <= 0 {
+		return seg.A
+	}
+	if t >= 1 {
+		return seg.B
+	}
+	return Point{
+		X: seg.A.X + t*(seg.B.X-seg.A.X),
+		Y: seg.A.Y + t*(seg.B.Y-seg.A.Y),
+	}
+}
+
+// Distance returns the distance from the point to the nearest point on the
+// segment, which is always the same as the distance to ClosestPoint.
+func (seg Segment) Distance(point Point) float64 {
+	return point.Distance(seg.ClosestPoint(point))
+}
+
+// SignedDistance returns the distance from the point to the segment.
+// The distance is positive when the point is to the left of the segment,
+// going from A to B, and negative when to the right.
+func (seg Segment) SignedDistance(point Point) float64 {
+	dist := seg.Distance(point)
+	cross := (seg.B.X-seg.A.X)*(point.Y-seg.A.Y) -
+		(seg.B.Y-seg.A.Y)*(point.X-seg.A.X)
+	if cross < 0 {
+		return -dist
+	}
+	return dist
+}
+
+// Subdivide returns n+1 points that divide the segment into n equal parts,
+// starting with A and ending with B. An n of zero or less returns A and B.
+func (seg Segment) Subdivide(n int) []Point {
+	if n <= 0 {
+		n = 1
+	}
+	points := make([]Point, n+1)
+	points[0], points[n] = seg.A, seg.B
+	for i := 1; i < n; i++ {
+		points[i] = seg.A.Lerp(seg.B, float64(i)/float64(n))
+	}
+	return points
+}
+
+// Normal returns the unit vector that is perpendicular to the segment,
+// pointing to the left when going from A to B. Returns the zero point for a
+// zero-length segment.
+func (seg Segment) Normal() Point {
+	dx, dy := seg.B.X-seg.A.X, seg.B.Y-seg.A.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return Point{}
+	}
+	return Point{X: -dy / length, Y: dx / length}
+}
+
+// DistanceToSegment returns the distance between the nearest points of the
+// segment and the other segment. Returns zero if the segments intersect.
+func (seg Segment) DistanceToSegment(other Segment) float64 {
+	if seg.IntersectsSegment(other) {
+		return 0
+	}
+	// without an intersection, one of the nearest points is an endpoint
+	return math.Min(
+		math.Min(seg.Distance(other.A), seg.Distance(other.B)),
+		math.Min(other.Distance(seg.A), other.Distance(seg.B)),
+	)
+}
+
+// CrossTrackDistance returns the perpendicular distance from the point to
+// the infinite line through the segment. The distance is positive when the
+// point is to the left of the line, going from A to B, and negative when to
+// the right. Returns zero for a zero-length segment.
+func (seg Segment) CrossTrackDistance(point Point) float64 {
+	return seg.Normal().Dot(Point{point.X - seg.A.X, point.Y - seg.A.Y})
+}
+
+// AlongTrackDistance returns the distance from A to the projection of the
+// point onto the infinite line through the segment. The distance is negative
+// when the projection is behind A, and more than the length of the segment
+// when it's past B. Returns zero for a zero-length segment.
+func (seg Segment) AlongTrackDistance(point Point) float64 {
+	normal := seg.Normal()
+	dir := Point{normal.Y, -normal.X}
+	return dir.Dot(Point{point.X - seg.A.X, point.Y - seg.A.Y})
+}
+
+// SignedAreaContribution returns the term that the segment adds to the
+// shoelace sum of a ring, which is (B.X-A.X)*(B.Y+A.Y). The sum over all of
+// the segments of a ring is positive when the ring is clockwise and negative
+// when counter-clockwise, and its magnitude is twice the area of the ring.
+func (seg Segment) SignedAreaContribution() float64 {
+	return (seg.B.X - seg.A.X) * (seg.B.Y + seg.A.Y)
+}
+
 func (seg Segment) CollinearPoint(point Point) bool {
 	cmpx, cmpy := point.X-seg.A.X, point.Y-seg.A.Y
 	rx, ry := seg.B.X-seg.A.X, seg.B.Y-seg.A.Y
@@ -38,6 +154,58 @@ func (seg Segment) CollinearPoint(point Point) bool {
 	return cmpxr == 0
 }
 
+// CollinearPointTolerance detects if the point is on the infinite line
+// through the segment, or is within epsilon of it. The cross product is
+// divided by the length of the segment, so epsilon is a distance in the same
+// units as the points, and does not depend on the length of the segment. For
+// a zero-length segment, the point must be within epsilon of A.
+func (seg Segment) CollinearPointTolerance(point Point, epsilon float64) bool {
+	cmpx, cmpy := point.X-seg.A.X, point.Y-seg.A.Y
+	rx, ry := seg.B.X-seg.A.X, seg.B.Y-seg.A.Y
+	length := math.Hypot(rx, ry)
+	if length == 0 {
+		return math.Hypot(cmpx, cmpy) <= epsilon
+	}
+	return math.Abs(cmpx*ry-cmpy*rx)/length <= epsilon
+}
+
+// CollinearOverlap returns the part of the segment that is shared with the
+// other segment, in the direction of the segment. Returns false if the
+// segments are not collinear or do not overlap. Segments are collinear when
+// both points of the other segment are within a tiny distance, relative to
+// the length of the segment, from the line through the segment. Segments that
+// only touch at an endpoint return a zero-length segment.
+func (seg Segment) CollinearOverlap(other Segment) (Segment, bool) {
+	const eps = 1e-12
+	dx, dy := seg.B.X-seg.A.X, seg.B.Y-seg.A.Y
+	l2 := dx*dx + dy*dy
+	if l2 == 0 {
+		return Segment{}, false
+	}
+	for _, point := range [...]Point{other.A, other.B} {
+		cross := dx*(point.Y-seg.A.Y) - dy*(point.X-seg.A.X)
+		if math.Abs(cross) > eps*l2 {
+			return Segment{}, false
+		}
+	}
+	t0, t1 := seg.ClosestParameter(other.A), seg.ClosestParameter(other.B)
+	p0, p1 := other.A, other.B
+	if t0 > t1 {
+		t0, t1 = t1, t0
+		p0, p1 = p1, p0
+	}
+	if t1 < 0 || t0 > 1 {
+		return Segment{}, false
+	}
+	if t0 <= 0 {
+		p0 = seg.A
+	}
+	if t1 >= 1 {
+		p1 = seg.B
+	}
+	return Segment{p0, p1}, true
+}
+
 func (seg Segment) ContainsPoint(point Point) bool {
 	return seg.Raycast(point).On
 }
@@ -118,7 +286,87 @@ func (seg Segment) IntersectsSegment(other Segment) bool {
 	return (t >= 0) && (t <= 1) && (u >= 0) && (u <= 1)
 }
 
+// IntersectsSegmentTolerance detects if the segment intersects with the other
+// segment, or comes within tolerance of it. This allows for segments that
+// should touch, but miss by a tiny amount due to floating-point rounding.
+// A tolerance of zero, or less, is the same as IntersectsSegment.
+func (seg Segment) IntersectsSegmentTolerance(other Segment,
+	tolerance float64) bool {
+	if tolerance <= 0 {
+		return seg.IntersectsSegment(other)
+	}
+	return seg.DistanceToSegment(other) <= tolerance
+}
+
+// appendIntersectionPoints appends the points where two segments intersect
+// to dst. Segments that cross or touch have one point, and collinear
+// segments that overlap have a point for each end of the overlap.
+func appendIntersectionPoints(dst []Point, seg, other Segment) []Point {
+	if !seg.Rect().IntersectsRect(other.Rect()) {
+		return dst
+	}
+	rx, ry := seg.B.X-seg.A.X, seg.B.Y-seg.A.Y
+	sx, sy := other.B.X-other.A.X, other.B.Y-other.A.Y
+	denom := rx*sy - ry*sx
+	if denom == 0 {
+		// parallel, collinear, or degenerate
+		n := len(dst)
+		add := func(point Point) {
+			for _, p := range dst[n:] {
+				if p == point {
+					return
+				}
+			}
+			dst = append(dst, point)
+		}
+		for _, point := range [...]Point{seg.A, seg.B} {
+			if other.ContainsPoint(point) {
+				add(point)
+			}
+		}
+		for _, point := range [...]Point{other.A, other.B} {
+			if seg.ContainsPoint(point) {
+				add(point)
+			}
+		}
+		return dst
+	}
+	// prefer exact shared endpoints
+	for _, point := range [...]Point{seg.A, seg.B} {
+		if point == other.A || point == other.B {
+			return append(dst, point)
+		}
+	}
+	qx, qy := other.A.X-seg.A.X, other.A.Y-seg.A.Y
+	t := (qx*sy - qy*sx) / denom
+	u := (qx*ry - qy*rx) / denom
+	if t < 0 || t > 1 || u < 0 || u > 1 {
+		return dst
+	}
+	return append(dst, Point{X: seg.A.X + t*rx, Y: seg.A.Y + t*ry})
+}
+
 // ContainsSegment returns true if segment contains other segment
 func (seg Segment) ContainsSegment(other Segment) bool {
 	return seg.Raycast(other.A).On && seg.Raycast(other.B).On
 }
+
+// ClipRect returns the part of the segment that is inside of the rectangle,
+// using the Liang–Barsky algorithm. The clipped segment has the same
+// direction as the segment, and a segment that is fully inside of the
+// rectangle is returned unchanged. Returns false if no part of the segment
+// is inside of the rectangle, including its edges.
+func (seg Segment) ClipRect(rect Rect) (Segment, bool) {
+	t0, t1, ok := rectClipSegment(rect, seg)
+	if !ok {
+		return Segment{}, false
+	}
+	clipped := seg
+	if t0 > 0 {
+		clipped.A = seg.A.Lerp(seg.B, t0)
+	}
+	if t1 < 1 {
+		clipped.B = seg.A.Lerp(seg.B, t1)
+	}
+	return clipped, true
+}
@@ -6,6 +6,7 @@ package geometry
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"testing"
 )
@@ -361,3 +362,283 @@ func TestSegmentMove(t *testing.T) {
 func TestSegmentRect(t *testing.T) {
 	expect(t, S(12, 13, 11, 12).Rect() == R(11, 12, 12, 13))
 }
+
+func TestSegmentDistance(t *testing.T) {
+	expect(t, S(0, 0, 10, 0).Distance(P(5, 5)) == 5)
+	expect(t, S(0, 0, 10, 0).Distance(P(5, -5)) == 5)
+	expect(t, S(0, 0, 10, 0).Distance(P(5, 0)) == 0)
+	expect(t, S(0, 0, 10, 0).Distance(P(-3, 4)) == 5)
+	expect(t, S(0, 0, 10, 0).Distance(P(13, -4)) == 5)
+	expect(t, S(1, 1, 1, 1).Distance(P(4, 5)) == 5)
+	for i := 0; i < 100; i++ {
+		p := P(float64(i%10)-2, float64(i/10)-2)
+		dist := S(0, 0, 5, 3).Distance(p)
+		expect(t, math.Abs(dist-distPointToSegment(p, S(0, 0, 5, 3))) < 1e-12)
+	}
+}
+
+func TestSegmentSignedDistance(t *testing.T) {
+	expect(t, S(0, 0, 10, 0).SignedDistance(P(5, 5)) == 5)
+	expect(t, S(0, 0, 10, 0).SignedDistance(P(5, -5)) == -5)
+	expect(t, S(10, 0, 0, 0).SignedDistance(P(5, 5)) == -5)
+	expect(t, S(0, 0, 10, 0).SignedDistance(P(5, 0)) == 0)
+}
+
+func TestSegmentClosestPoint(t *testing.T) {
+	seg := S(0, 0, 10, 0)
+	expect(t, seg.ClosestPoint(P(5, 5)) == P(5, 0))
+	expect(t, seg.ClosestPoint(P(5, -5)) == P(5, 0))
+	expect(t, seg.ClosestPoint(P(-3, 4)) == P(0, 0))
+	expect(t, seg.ClosestPoint(P(13, -4)) == P(10, 0))
+	expect(t, S(1, 1, 1, 1).ClosestPoint(P(4, 5)) == P(1, 1))
+	// Distance and ClosestPoint must agree for points along and beyond the
+	// segment.
+	for _, seg := range []Segment{S(0, 0, 10, 0), S(2, 3, 7, -4), S(1, 1, 1, 1)} {
+		for x := -10.0; x <= 20; x += 1.5 {
+			for y := -10.0; y <= 20; y += 1.5 {
+				p := P(x, y)
+				expect(t, seg.Distance(p) == p.Distance(seg.ClosestPoint(p)))
+				expect(t, seg.Distance(p) <= p.Distance(seg.A))
+				expect(t, seg.Distance(p) <= p.Distance(seg.B))
+			}
+		}
+	}
+}
+
+func TestSegmentClosestParameter(t *testing.T) {
+	seg := S(0, 0, 10, 0)
+	expect(t, seg.ClosestParameter(P(5, 5)) == 0.5)
+	expect(t, seg.ClosestParameter(P(-5, 3)) == -0.5)
+	expect(t, seg.ClosestParameter(P(25, -3)) == 2.5)
+	expect(t, seg.ClosestParameter(P(0, 0)) == 0)
+	expect(t, seg.ClosestParameter(P(10, 0)) == 1)
+	expect(t, S(1, 1, 1, 1).ClosestParameter(P(4, 5)) == 0)
+	// points beyond the endpoints clamp to A or B
+	expect(t, S(2, 2, 4, 4).ClosestPoint(P(-1, 0)) == P(2, 2))
+	expect(t, S(2, 2, 4, 4).ClosestPoint(P(9, 7)) == P(4, 4))
+	expect(t, S(4, 4, 2, 2).ClosestPoint(P(9, 7)) == P(4, 4))
+}
+
+func TestSegmentCollinearOverlap(t *testing.T) {
+	// fully overlapping
+	seg, ok := S(0, 0, 10, 0).CollinearOverlap(S(0, 0, 10, 0))
+	expect(t, ok && seg == S(0, 0, 10, 0))
+	seg, ok = S(0, 0, 10, 0).CollinearOverlap(S(10, 0, 0, 0))
+	expect(t, ok && seg == S(0, 0, 10, 0))
+	seg, ok = S(0, 0, 10, 0).CollinearOverlap(S(-5, 0, 15, 0))
+	expect(t, ok && seg == S(0, 0, 10, 0))
+	seg, ok = S(0, 0, 10, 0).CollinearOverlap(S(2, 0, 4, 0))
+	expect(t, ok && seg == S(2, 0, 4, 0))
+	// partially overlapping
+	seg, ok = S(0, 0, 10, 10).CollinearOverlap(S(5, 5, 15, 15))
+	expect(t, ok && seg == S(5, 5, 10, 10))
+	seg, ok = S(10, 10, 0, 0).CollinearOverlap(S(5, 5, 15, 15))
+	expect(t, ok && seg == S(10, 10, 5, 5))
+	seg, ok = S(0, 0, 10, 0).CollinearOverlap(S(-3, 0, 4, 0))
+	expect(t, ok && seg == S(0, 0, 4, 0))
+	expect(t, math.Abs(seg.A.Distance(seg.B)-4) < 1e-12)
+	// touching endpoints
+	seg, ok = S(0, 0, 10, 0).CollinearOverlap(S(10, 0, 20, 0))
+	expect(t, ok && seg == S(10, 0, 10, 0))
+	// collinear but apart
+	_, ok = S(0, 0, 10, 0).CollinearOverlap(S(11, 0, 20, 0))
+	expect(t, !ok)
+	// not collinear
+	_, ok = S(0, 0, 10, 0).CollinearOverlap(S(0, 1, 10, 1))
+	expect(t, !ok)
+	_, ok = S(0, 0, 10, 0).CollinearOverlap(S(5, -5, 5, 5))
+	expect(t, !ok)
+	_, ok = S(0, 0, 10, 0).CollinearOverlap(S(0, 0, 10, 0.001))
+	expect(t, !ok)
+	// nearly collinear from floating point error
+	_, ok = S(0, 0, 0.3, 0.3).CollinearOverlap(S(0.1, 0.1, 0.1+0.2, 0.1+0.2))
+	expect(t, ok)
+	// degenerate
+	_, ok = S(1, 1, 1, 1).CollinearOverlap(S(0, 0, 2, 2))
+	expect(t, !ok)
+}
+
+func TestSegmentNormal(t *testing.T) {
+	expect(t, S(0, 0, 10, 0).Normal() == P(0, 1))
+	expect(t, S(10, 0, 0, 0).Normal() == P(0, -1))
+	expect(t, S(0, 0, 0, 5).Normal() == P(-1, 0))
+	normal := S(1, 2, 4, 6).Normal()
+	expect(t, math.Abs(math.Hypot(normal.X, normal.Y)-1) < 1e-15)
+	expect(t, pointsNear(normal, P(-0.8, 0.6), 1e-15))
+	// perpendicular and to the left
+	expect(t, math.Abs(normal.X*3+normal.Y*4) < 1e-15)
+	expect(t, S(1, 2, 4, 6).SignedDistance(P(1+normal.X, 2+normal.Y)) > 0)
+	expect(t, S(3, 3, 3, 3).Normal() == P(0, 0))
+}
+
+func TestSegmentSubdivide(t *testing.T) {
+	seg := S(0, 0, 10, 20)
+	for n := 1; n <= 10; n++ {
+		points := seg.Subdivide(n)
+		expect(t, len(points) == n+1)
+		expect(t, points[0] == seg.A && points[n] == seg.B)
+		for i := 1; i <= n; i++ {
+			expect(t, math.Abs(points[i].Distance(points[i-1])-
+				seg.A.Distance(seg.B)/float64(n)) < 1e-9)
+		}
+		if n%2 == 0 {
+			expect(t, points[n/2] == P(5, 10))
+		}
+	}
+	for _, n := range []int{0, -1} {
+		points := seg.Subdivide(n)
+		expect(t, len(points) == 2 && points[0] == seg.A && points[1] == seg.B)
+	}
+}
+
+func TestSegmentCrossTrackAlongTrack(t *testing.T) {
+	seg := S(0, 0, 10, 0)
+	// left
+	expect(t, seg.CrossTrackDistance(P(3, 4)) == 4)
+	expect(t, seg.AlongTrackDistance(P(3, 4)) == 3)
+	// right
+	expect(t, seg.CrossTrackDistance(P(7, -2)) == -2)
+	expect(t, seg.AlongTrackDistance(P(7, -2)) == 7)
+	// on the line, beyond the ends
+	expect(t, seg.CrossTrackDistance(P(15, 0)) == 0)
+	expect(t, seg.AlongTrackDistance(P(15, 0)) == 15)
+	expect(t, seg.AlongTrackDistance(P(-5, 1)) == -5)
+	// reversed direction flips the side
+	rev := S(10, 0, 0, 0)
+	expect(t, rev.CrossTrackDistance(P(3, 4)) == -4)
+	expect(t, rev.AlongTrackDistance(P(3, 4)) == 7)
+	// diagonal
+	diag := S(1, 2, 4, 6)
+	expect(t, math.Abs(diag.CrossTrackDistance(P(-3, 5))-5) < 1e-12)
+	expect(t, math.Abs(diag.AlongTrackDistance(P(4, 6))-5) < 1e-12)
+	// zero length
+	expect(t, S(3, 3, 3, 3).CrossTrackDistance(P(5, 5)) == 0)
+	expect(t, S(3, 3, 3, 3).AlongTrackDistance(P(5, 5)) == 0)
+}
+
+func TestSegmentClipRect(t *testing.T) {
+	rect := R(0, 0, 10, 10)
+	// crosses two opposite edges
+	clipped, ok := S(-5, 5, 15, 5).ClipRect(rect)
+	expect(t, ok && clipped == S(0, 5, 10, 5))
+	clipped, ok = S(5, 15, 5, -5).ClipRect(rect)
+	expect(t, ok && clipped == S(5, 10, 5, 0))
+	clipped, ok = S(-10, -5, 20, 10).ClipRect(rect)
+	expect(t, ok && clipped == S(0, 0, 10, 5))
+	// one endpoint inside
+	clipped, ok = S(5, 5, 15, 10).ClipRect(rect)
+	expect(t, ok && clipped == S(5, 5, 10, 7.5))
+	clipped, ok = S(-5, 0, 5, 5).ClipRect(rect)
+	expect(t, ok && clipped == S(0, 2.5, 5, 5))
+	// fully inside
+	clipped, ok = S(1, 2, 3, 4).ClipRect(rect)
+	expect(t, ok && clipped == S(1, 2, 3, 4))
+	clipped, ok = S(0, 0, 10, 10).ClipRect(rect)
+	expect(t, ok && clipped == S(0, 0, 10, 10))
+	// fully outside
+	_, ok = S(-5, -5, -1, 20).ClipRect(rect)
+	expect(t, !ok)
+	_, ok = S(-5, 8, 8, 21).ClipRect(rect)
+	expect(t, !ok)
+	_, ok = S(11, 0, 11, 10).ClipRect(rect)
+	expect(t, !ok)
+	// touches a corner
+	clipped, ok = S(-5, 5, 5, 15).ClipRect(rect)
+	expect(t, ok && clipped == S(0, 10, 0, 10))
+	// along an edge
+	clipped, ok = S(-5, 10, 5, 10).ClipRect(rect)
+	expect(t, ok && clipped == S(0, 10, 5, 10))
+	// zero length
+	clipped, ok = S(3, 3, 3, 3).ClipRect(rect)
+	expect(t, ok && clipped == S(3, 3, 3, 3))
+	_, ok = S(30, 3, 30, 3).ClipRect(rect)
+	expect(t, !ok)
+}
+
+func TestSegmentDistanceToSegment(t *testing.T) {
+	// crossing
+	expect(t, S(0, 0, 10, 10).DistanceToSegment(S(0, 10, 10, 0)) == 0)
+	// touching at an endpoint
+	expect(t, S(0, 0, 10, 0).DistanceToSegment(S(10, 0, 10, 10)) == 0)
+	// parallel
+	expect(t, S(0, 0, 10, 0).DistanceToSegment(S(2, 3, 8, 3)) == 3)
+	expect(t, S(0, 0, 10, 0).DistanceToSegment(S(-5, -2, 15, -2)) == 2)
+	// collinear with a gap
+	expect(t, S(0, 0, 10, 0).DistanceToSegment(S(13, 0, 20, 0)) == 3)
+	// perpendicular without touching
+	expect(t, S(0, 0, 10, 0).DistanceToSegment(S(5, 2, 5, 10)) == 2)
+	expect(t, S(0, 0, 10, 0).DistanceToSegment(S(14, -1, 14, 1)) == 4)
+	// nearest points are both endpoints
+	expect(t, S(0, 0, 10, 0).DistanceToSegment(S(13, 4, 20, 10)) == 5)
+	// symmetric
+	a, b := S(1, 2, 4, 9), S(6, 1, 9, -3)
+	expect(t, a.DistanceToSegment(b) == b.DistanceToSegment(a))
+	// zero length
+	expect(t, S(3, 4, 3, 4).DistanceToSegment(S(0, 0, 0, 0)) == 5)
+}
+
+func TestSegmentIntersectsSegmentTolerance(t *testing.T) {
+	// near miss at an endpoint
+	a, b := S(0, 0, 10, 0), S(5, 0.001, 5, 10)
+	expect(t, !a.IntersectsSegment(b))
+	expect(t, a.IntersectsSegmentTolerance(b, 0.01))
+	expect(t, b.IntersectsSegmentTolerance(a, 0.01))
+	expect(t, !a.IntersectsSegmentTolerance(b, 0.0001))
+	expect(t, !a.IntersectsSegmentTolerance(b, 0))
+	// parallel near miss
+	c := S(2, -0.001, 8, -0.001)
+	expect(t, a.IntersectsSegmentTolerance(c, 0.01))
+	expect(t, !a.IntersectsSegmentTolerance(c, 0.0001))
+	// zero tolerance is the exact test
+	for _, other := range []Segment{
+		S(0, 10, 10, 0), S(10, 0, 10, 10), S(13, 0, 20, 0), S(2, 3, 8, 3),
+		S(5, 5, 5, -5), S(-1, 0, 11, 0),
+	} {
+		expect(t, a.IntersectsSegmentTolerance(other, 0) ==
+			a.IntersectsSegment(other))
+		expect(t, a.IntersectsSegmentTolerance(other, -1) ==
+			a.IntersectsSegment(other))
+	}
+}
+
+func TestSegmentSignedAreaContribution(t *testing.T) {
+	expect(t, S(1, 2, 4, 6).SignedAreaContribution() == 24)
+	expect(t, S(4, 6, 1, 2).SignedAreaContribution() == -24)
+	for _, points := range [][]Point{octagon, concave1, AZ} {
+		for _, ring := range []Ring{
+			newRing(points, nil),
+			newRing(seriesCopyPoints(seriesReverse(newRing(points, nil))), nil),
+		} {
+			var sum float64
+			for i := 0; i < ring.NumSegments(); i++ {
+				sum += ring.SegmentAt(i).SignedAreaContribution()
+			}
+			expect(t, sum != 0)
+			expect(t, (sum > 0) == ring.Clockwise())
+			area := math.Abs(signedArea(seriesCopyPoints(ring)))
+			expect(t, math.Abs(math.Abs(sum)/2-area) < 1e-9*area)
+		}
+	}
+}
+
+func TestSegmentCollinearPointTolerance(t *testing.T) {
+	// a point that is 1e-9 off of a long segment
+	seg := S(0, 0, 1e6, 0)
+	point := P(5e5, 1e-9)
+	expect(t, !seg.CollinearPoint(point))
+	expect(t, seg.CollinearPointTolerance(point, 1e-6))
+	expect(t, !seg.CollinearPointTolerance(point, 1e-12))
+	// past the end of the segment, on the same line
+	expect(t, seg.CollinearPointTolerance(P(2e6, -1e-9), 1e-6))
+	// epsilon is a distance, regardless of the length of the segment
+	expect(t, S(0, 0, 1, 1).CollinearPointTolerance(P(0.5, 0.5+1e-3), 1e-3))
+	expect(t, S(0, 0, 1e4, 1e4).CollinearPointTolerance(P(5, 5+1e-3), 1e-3))
+	expect(t, !S(0, 0, 1e4, 1e4).CollinearPointTolerance(P(5, 5+1e-3), 5e-4))
+	// exact points are collinear at zero epsilon
+	expect(t, S(0, 0, 1, 1).CollinearPointTolerance(P(2, 2), 0))
+	expect(t, S(1, 0, 0, 1).CollinearPointTolerance(P(-1, 2), 0))
+	expect(t, !S(1, 0, 0, 1).CollinearPointTolerance(P(0, 0), 0.5))
+	// zero length
+	expect(t, S(1, 1, 1, 1).CollinearPointTolerance(P(1, 1.5), 0.5))
+	expect(t, !S(1, 1, 1, 1).CollinearPointTolerance(P(1, 2), 0.5))
+}
@@ -7,6 +7,7 @@ package geometry
 import (
 	"encoding/binary"
 	"math"
+	"sort"
 )
 
 // IndexKind is the kind of index to use in the options.
@@ -33,6 +34,18 @@ func (kind IndexKind) String() string {
 type IndexOptions struct {
 	Kind      IndexKind
 	MinPoints int
+	// BulkLoad inserts the segments into the index in the order of the
+	// Hilbert curve values of their centers, rather than in the order of the
+	// points. This makes a more balanced tree for input that is already
+	// sorted, such as points that are sorted by X. The index format is the
+	// same either way.
+	BulkLoad bool
+	// MaxNodeItems is the number of segments that a quadtree node holds
+	// before it's split. Zero uses the default of 12.
+	MaxNodeItems int
+	// MaxDepth is the depth where quadtree nodes are no longer split. Zero
+	// uses the default of 64.
+	MaxDepth int
 }
 
 var (
@@ -43,6 +56,14 @@ var (
 // Series is just a series of points with utilities for efficiently accessing
 // segments from rectangle queries, making stuff like point-in-polygon lookups
 // very quick.
+//
+// The methods of a series that is created by NewLine, NewRing, or NewPoly
+// never change it, so Search and DistanceToSeries may be called on the same
+// series from many goroutines at once. Each call only reads the points and
+// the compressed index, and any working memory is per call. This does not
+// hold while the series is being changed, such as while the points returned
+// by RawPoints are modified, while Recompute is running, or while a
+// MutableSeries is being changed.
 type Series interface {
 	Rect() Rect
 	Empty() bool
@@ -56,6 +77,7 @@ type Series interface {
 	RawPoints() []Point
 	Closed() bool
 	Search(rect Rect, iter func(seg Segment, index int) bool)
+	WithinRect(rect Rect) bool
 }
 
 func seriesCopyPoints(series Series) []Point {
@@ -68,13 +90,14 @@ func seriesCopyPoints(series Series) []Point {
 
 // baseSeries is a concrete type containing all that is needed to make a Series.
 type baseSeries struct {
-	closed    bool      // points create a closed shape
-	clockwise bool      // points move clockwise
-	convex    bool      // points create a convex shape
-	indexKind IndexKind // index kind
-	index     []byte    // actual index
-	rect      Rect      // minumum bounding rectangle
-	points    []Point   // original points
+	closed    bool         // points create a closed shape
+	clockwise bool         // points move clockwise
+	convex    bool         // points create a convex shape
+	indexKind IndexKind    // index kind
+	index     []byte       // actual index
+	indexOpts IndexOptions // options used to build the index
+	rect      Rect         // minumum bounding rectangle
+	points    []Point      // original points
 }
 
 var _ Series = &baseSeries{}
@@ -97,6 +120,7 @@ func makeSeries(
 	series.convex, series.rect, series.clockwise = processPoints(points, closed)
 	if opts.MinPoints != 0 && len(points) >= opts.MinPoints {
 		series.indexKind = opts.Kind
+		series.indexOpts = *opts
 		series.buildIndex()
 	}
 	return series
@@ -114,20 +138,99 @@ func (series *baseSeries) Clockwise() bool {
 	return series.clockwise
 }
 
+// Clone returns a deep copy of the series. The points and the index are
+// copied into new arrays, so the clone shares no memory with the original.
+//
+// The points returned by RawPoints are not copied and may be modified by the
+// caller, followed by a call to Recompute. A clone guarantees that such
+// changes to one series do not affect the other, which is also how to keep
+// reading a series from other goroutines while a copy of it is changed.
+func (series *baseSeries) Clone() Series {
+	nseries := *series
+	nseries.points = append([]Point(nil), series.points...)
+	nseries.index = append([]byte(nil), series.index...)
+	return &nseries
+}
+
+// Recompute recalculates the convex, clockwise, and rect values of the series
+// from its current points, and rebuilds the index if the series has one. This
+// is needed after the points returned by RawPoints are modified, which
+// otherwise leaves those values out of date, and the index searching the
+// wrong quads. It's not safe to call while the series is in use by other
+// goroutines.
+func (series *baseSeries) Recompute() {
+	series.convex, series.rect, series.clockwise =
+		processPoints(series.points, series.closed)
+	if len(series.index) > 0 {
+		series.clearIndex()
+		series.buildIndex()
+	}
+}
+
+// Move returns a new series with every point moved by the deltas. A
+// translation does not change the shape, so the convex and clockwise flags
+// are copied, and the rectangle is moved rather than recalculated. The index
+// only refers to segments by their position in the quadtree, so it's reused
+// as long as rounding did not nudge a segment out of its quad.
 func (series *baseSeries) Move(deltaX, deltaY float64) Series {
-	points := make([]Point, len(series.points))
+	nseries := *series
+	nseries.points = make([]Point, len(series.points))
 	for i := 0; i < len(series.points); i++ {
-		points[i].X = series.points[i].X + deltaX
-		points[i].Y = series.points[i].Y + deltaY
+		nseries.points[i].X = series.points[i].X + deltaX
+		nseries.points[i].Y = series.points[i].Y + deltaY
+	}
+	if len(series.points) > 0 {
+		nseries.rect = series.rect.Move(deltaX, deltaY)
 	}
-	nseries := makeSeries(points, false, series.closed, nil)
-	nseries.indexKind = series.indexKind
-	if len(series.Index()) > 0 {
+	if len(nseries.index) > 0 &&
+		!qCompressContained(nseries.index, 5, &nseries, nseries.rect) {
+		nseries.clearIndex()
 		nseries.buildIndex()
 	}
 	return &nseries
 }
 
+// Transform returns a new series with the affine transformation applied to
+// every point. The index is rebuilt with the options of the original series.
+func (series *baseSeries) Transform(m Affine) Series {
+	points := make([]Point, len(series.points))
+	for i := 0; i < len(series.points); i++ {
+		points[i] = m.Apply(series.points[i])
+	}
+	nseries := makeSeries(points, false, series.closed,
+		seriesIndexOptions(series))
+	return &nseries
+}
+
+// RotatedBounds returns the bounding rectangle that the series would have
+// after being rotated counter-clockwise around origin by radians.
+// When tight is false the four corners of the current rectangle are rotated,
+// which is quick but may produce a larger rectangle than needed. When tight
+// is true every point is rotated.
+func (series *baseSeries) RotatedBounds(
+	origin Point, radians float64, tight bool,
+) Rect {
+	if len(series.points) == 0 {
+		return Rect{}
+	}
+	if !tight {
+		return series.rect.RotatedBounds(origin, radians)
+	}
+	m := Translate(-origin.X, -origin.Y).
+		Multiply(Rotation(radians)).
+		Multiply(Translate(origin.X, origin.Y))
+	var bounds Rect
+	for i, point := range series.points {
+		point = m.Apply(point)
+		if i == 0 {
+			bounds = Rect{point, point}
+		} else {
+			bounds = bounds.Union(Rect{point, point})
+		}
+	}
+	return bounds
+}
+
 // Empty returns true if the series does not take up space.
 func (series *baseSeries) Empty() bool {
 	if series == nil {
@@ -184,6 +287,141 @@ func (series *baseSeries) Search(
 	}
 }
 
+// CountSearchBatch returns the number of segments that intersect each of the
+// provided rectangles. The index is traversed once for all rectangles, which
+// is quicker than performing a Search for each one, particularly when the
+// rectangles are clustered together.
+func (series *baseSeries) CountSearchBatch(rects []Rect) []int {
+	counts := make([]int, len(rects))
+	if len(series.index) == 0 {
+		n := series.NumSegments()
+		for i := 0; i < n; i++ {
+			srect := series.SegmentAt(i).Rect()
+			for j := range rects {
+				if srect.IntersectsRect(rects[j]) {
+					counts[j]++
+				}
+			}
+		}
+	} else {
+		active := make([]int, 0, len(rects))
+		for j := range rects {
+			if series.rect.IntersectsRect(rects[j]) {
+				active = append(active, j)
+			}
+		}
+		if len(active) > 0 {
+			data := series.index
+			n := binary.LittleEndian.Uint32(data[1:])
+			data = data[:n:n]
+			qCompressCountBatch(data, 5, series, series.rect, rects, active,
+				counts)
+		}
+	}
+	return counts
+}
+
+// CountIntersectingSegments returns the number of segments that intersect
+// the rectangle, which is the same as counting the segments from Search, but
+// without the overhead of calling an iterator for each segment.
+func CountIntersectingSegments(series Series, rect Rect) int {
+	base := seriesBase(series)
+	if base == nil || len(base.index) == 0 {
+		var count int
+		series.Search(rect, func(_ Segment, _ int) bool {
+			count++
+			return true
+		})
+		return count
+	}
+	data := base.index
+	n := binary.LittleEndian.Uint32(data[1:])
+	data = data[:n:n]
+	return qCompressCount(data, 5, base, base.rect, rect)
+}
+
+// WalkIndex calls iter for each node in the index of the series, starting at
+// the root, which has a depth of zero. The segmentCount is the number of
+// segments that are stored directly in the node, not including the child
+// nodes. Returning false from iter stops the walk. Nothing is reported for a
+// series without an index. This is useful for visualizing the index and for
+// tuning the IndexOptions.
+func WalkIndex(
+	series Series,
+	iter func(bounds Rect, depth int, segmentCount int) bool,
+) {
+	index := series.Index()
+	if len(index) == 0 {
+		return
+	}
+	bounds := series.Rect()
+	if ms, ok := series.(*MutableSeries); ok {
+		bounds = ms.bounds
+	}
+	n := binary.LittleEndian.Uint32(index[1:])
+	index = index[:n:n]
+	qCompressWalk(index, 5, bounds, 0, iter)
+}
+
+// ShouldIndex returns true if the series would likely benefit from having an
+// index. An index is worthwhile when there are enough segments to outweigh
+// the cost of traversing the index, and when the segments are generally
+// small compared to the bounds of the series, which allows for the quadtree
+// to separate them. Long segments that span most of the bounds cannot be
+// separated and end up being scanned anyway.
+func (series *baseSeries) ShouldIndex() bool {
+	n := series.NumSegments()
+	if n < DefaultIndexOptions.MinPoints {
+		return false
+	}
+	size := math.Max(series.rect.Max.X-series.rect.Min.X,
+		series.rect.Max.Y-series.rect.Min.Y)
+	if size == 0 {
+		return false
+	}
+	var total float64
+	for i := 0; i < n; i++ {
+		rect := series.SegmentAt(i).Rect()
+		total += math.Max(rect.Max.X-rect.Min.X, rect.Max.Y-rect.Min.Y)
+	}
+	// the average segment must be smaller than a quad at the second level of
+	// the quadtree.
+	return total/float64(n) < size/4
+}
+
+// WithinRect returns true if the series is fully inside of the rectangle.
+func (series *baseSeries) WithinRect(rect Rect) bool {
+	return rect.ContainsRect(series.rect)
+}
+
+// CoverageCounts returns the number of segments that are fully inside of the
+// rectangle, and the number of segments that cross its boundary.
+func (series *baseSeries) CoverageCounts(rect Rect) (inside, crossing int) {
+	series.Search(rect, func(seg Segment, idx int) bool {
+		if rect.ContainsSegment(seg) {
+			inside++
+		} else if rect.IntersectsSegment(seg) {
+			crossing++
+		}
+		return true
+	})
+	return inside, crossing
+}
+
+// seriesBase returns the baseSeries of a series, or nil if the series is not
+// built on a baseSeries.
+func seriesBase(series Series) *baseSeries {
+	switch series := series.(type) {
+	case *baseSeries:
+		return series
+	case *Line:
+		return &series.baseSeries
+	case *MutableSeries:
+		return series.base()
+	}
+	return nil
+}
+
 // DistanceToSeries returns an arbritary distance to a Series.
 // All the calculations are performed within two functions, that must be
 // provided by the caller:
@@ -196,20 +434,20 @@ func DistanceToSeries(
 	distToSegment func(seg Segment) float64,
 ) (seg Segment, idx int, dist float64) {
 	dist = math.NaN()
-	index := series.Index()
-	base, ok := series.(*baseSeries)
-	if !ok || len(index) == 0 {
+	base := seriesBase(series)
+	if base == nil || len(base.index) == 0 {
 		n := series.NumSegments()
 		for i := 0; i < n; i++ {
 			sseg := series.SegmentAt(i)
 			sdist := distToSegment(sseg)
 			if i == 0 || sdist < dist {
 				seg = sseg
+				idx = i
 				dist = sdist
 			}
 		}
 	} else {
-		data := index
+		data := base.index
 		n := binary.LittleEndian.Uint32(data[1:])
 		data = data[:n:n]
 		// skip over the first 5 bytes.
@@ -220,6 +458,766 @@ func DistanceToSeries(
 	return seg, idx, dist
 }
 
+// DistanceMetric calculates the distances that are needed to find the
+// nearest segment in a series.
+type DistanceMetric interface {
+	// RectDist returns the distance to a rectangle, which must not be more
+	// than the distance to any segment inside of the rectangle.
+	RectDist(rect Rect) float64
+	// SegDist returns the distance to a segment.
+	SegDist(seg Segment) float64
+}
+
+// DistanceToSeriesMetric is the same as DistanceToSeries, but using the
+// metric to calculate the distances.
+func DistanceToSeriesMetric(series Series, m DistanceMetric) (
+	seg Segment, idx int, dist float64,
+) {
+	if m, ok := m.(EuclideanMetric); ok {
+		// Compare the squared distances, and only take the square root of
+		// the nearest.
+		point := Point(m)
+		seg, idx, dist = DistanceToSeries(series,
+			func(rect Rect) float64 {
+				return rectDistSqToPoint(rect, point)
+			},
+			func(seg Segment) float64 {
+				return point.DistanceSquared(seg.ClosestPoint(point))
+			},
+		)
+		return seg, idx, math.Sqrt(dist)
+	}
+	return DistanceToSeries(series, m.RectDist, m.SegDist)
+}
+
+// EuclideanMetric is a DistanceMetric for the euclidean distance from a
+// point.
+type EuclideanMetric Point
+
+// RectDist returns the distance from the point to the nearest point in the
+// rectangle.
+func (m EuclideanMetric) RectDist(rect Rect) float64 {
+	return rect.DistanceToPoint(Point(m))
+}
+
+// SegDist returns the distance from the point to the nearest point on the
+// segment.
+func (m EuclideanMetric) SegDist(seg Segment) float64 {
+	return seg.Distance(Point(m))
+}
+
+// HausdorffDistance returns the Hausdorff distance between two series, which
+// is the largest distance from any point of one series to the nearest segment
+// of the other series. This is useful for measuring how much a simplified
+// series deviates from the original. The index of each series is used to
+// find the nearest segments. Returns NaN if either series has no segments.
+func HausdorffDistance(a, b Series) float64 {
+	if a.NumSegments() == 0 || b.NumSegments() == 0 {
+		return math.NaN()
+	}
+	return math.Max(hausdorffDirected(a, b), hausdorffDirected(b, a))
+}
+
+// hausdorffDirected returns the largest distance from a point of a to the
+// nearest segment of b.
+func hausdorffDirected(a, b Series) float64 {
+	var max float64
+	n := a.NumPoints()
+	for i := 0; i < n; i++ {
+		_, _, dist := DistanceToSeriesMetric(b, EuclideanMetric(a.PointAt(i)))
+		if dist > max {
+			max = dist
+		}
+	}
+	return max
+}
+
+// DiscreteFrechetDistance returns the discrete Fréchet distance between the
+// points of two series. Unlike the Hausdorff distance, the order of the
+// points matters, which makes it useful for comparing trajectories, such as
+// GPS tracks that go in the same or opposite directions.
+// This uses dynamic programming over every pair of points, which is
+// O(n*m) time. Returns NaN if either series has no points.
+func DiscreteFrechetDistance(a, b Series) float64 {
+	n, m := a.NumPoints(), b.NumPoints()
+	if n == 0 || m == 0 {
+		return math.NaN()
+	}
+	prev := make([]float64, m)
+	curr := make([]float64, m)
+	for i := 0; i < n; i++ {
+		pa := a.PointAt(i)
+		for j := 0; j < m; j++ {
+			dist := pa.Distance(b.PointAt(j))
+			switch {
+			case i == 0 && j == 0:
+				curr[j] = dist
+			case i == 0:
+				curr[j] = math.Max(curr[j-1], dist)
+			case j == 0:
+				curr[j] = math.Max(prev[j], dist)
+			default:
+				curr[j] = math.Max(
+					math.Min(prev[j], math.Min(prev[j-1], curr[j-1])), dist)
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[m-1]
+}
+
+// IsSimple returns true if the series does not intersect itself.
+// Adjacent segments may share their common endpoint, and the first and last
+// segments may also share an endpoint when the series is closed, but any
+// other contact between two segments, including touching at a single
+// vertex, means that the series is not simple.
+func IsSimple(series Series) bool {
+	n := series.NumSegments()
+	if n < 2 {
+		return true
+	}
+	// the first and last segments are adjacent when the series loops back to
+	// its starting point.
+	loops := series.Closed() || series.PointAt(0) == series.SegmentAt(n-1).B
+	simple := true
+	for i := 0; i < n && simple; i++ {
+		seg := series.SegmentAt(i)
+		series.Search(seg.Rect(), func(other Segment, j int) bool {
+			if j <= i {
+				return true
+			}
+			if j == i+1 || (loops && i == 0 && j == n-1) {
+				// adjacent segments must only touch at the shared point
+				var a, b Point
+				if j == i+1 {
+					a, b = seg.A, other.B
+				} else {
+					a, b = seg.B, other.A
+				}
+				if a != b && !seg.ContainsPoint(b) && !other.ContainsPoint(a) {
+					return true
+				}
+			} else if !seg.IntersectsSegment(other) {
+				return true
+			}
+			simple = false
+			return false
+		})
+	}
+	return simple
+}
+
+// SeriesIntersection is a point where two series intersect.
+type SeriesIntersection struct {
+	Point      Point
+	AIdx, BIdx int // segment index in each series
+}
+
+// SeriesIntersections returns the points where the segments of two series
+// intersect. Each segment of b is used to search a, using a's index when
+// available, and intersection points are only calculated for the candidate
+// segments that are found. A crossing that is shared by multiple segments,
+// such as at a vertex, is only returned once using the lowest segment
+// indexes. Collinear segments that overlap intersect at the ends of the
+// overlap. The results are ordered by AIdx and then BIdx.
+func SeriesIntersections(a, b Series) []SeriesIntersection {
+	var results []SeriesIntersection
+	var points []Point
+	n := b.NumSegments()
+	for j := 0; j < n; j++ {
+		bseg := b.SegmentAt(j)
+		a.Search(bseg.Rect(), func(aseg Segment, i int) bool {
+			points = appendIntersectionPoints(points[:0], aseg, bseg)
+			for _, point := range points {
+				results = append(results, SeriesIntersection{point, i, j})
+			}
+			return true
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].AIdx != results[j].AIdx {
+			return results[i].AIdx < results[j].AIdx
+		}
+		return results[i].BIdx < results[j].BIdx
+	})
+	seen := make(map[Point]bool, len(results))
+	var k int
+	for _, result := range results {
+		if !seen[result.Point] {
+			seen[result.Point] = true
+			results[k] = result
+			k++
+		}
+	}
+	return results[:k]
+}
+
+// seriesIndexOptions returns index options that will produce a series with
+// the same kind of index as the provided series, including the node limits
+// and bulk loading that the index was built with.
+func seriesIndexOptions(series Series) *IndexOptions {
+	index := series.Index()
+	if len(index) == 0 {
+		return NoIndexing
+	}
+	if ms, ok := series.(*MutableSeries); ok {
+		opts := ms.opts
+		return &opts
+	}
+	if base := seriesBase(series); base != nil && base.indexOpts.MinPoints != 0 {
+		opts := base.indexOpts
+		return &opts
+	}
+	return &IndexOptions{
+		Kind:      IndexKind(index[0]),
+		MinPoints: DefaultIndexOptions.MinPoints,
+	}
+}
+
+// SplitAt splits the series into two open series at the point index. The
+// first series has the points up to and including the index, and the second
+// has the points starting at the index, thus the point at the index is shared
+// by both. Each series is indexed in the same manner as the original series.
+//
+// A closed series is opened into a single series that starts and ends at the
+// point index, and the second returned series is empty.
+//
+// Panics if the index is out of range.
+func SplitAt(series Series, index int) (Series, Series) {
+	n := series.NumPoints()
+	if index < 0 || index >= n {
+		panic("index out of range")
+	}
+	opts := seriesIndexOptions(series)
+	points := seriesCopyPoints(series)
+	if series.Closed() {
+		if points[n-1] == points[0] {
+			points = points[:n-1]
+		}
+		opened := make([]Point, 0, len(points)+1)
+		opened = append(opened, points[index%len(points):]...)
+		opened = append(opened, points[:index%len(points)+1]...)
+		first := makeSeries(opened, false, false, opts)
+		return &first, &baseSeries{}
+	}
+	first := makeSeries(points[:index+1:index+1], false, false, opts)
+	second := makeSeries(points[index:], false, false, opts)
+	return &first, &second
+}
+
+// Slice returns an open series of the points from start up to, but not
+// including, end. The new series is a view that shares the points of the
+// original series, which are not copied, so changing the points of either
+// series changes both. The new series has its own index, which is built in
+// the same manner as the original series.
+//
+// Panics if start or end is out of range, like slicing.
+func Slice(series Series, start, end int) Series {
+	points := series.RawPoints()[start:end:end]
+	nseries := makeSeries(points, false, false, seriesIndexOptions(series))
+	return &nseries
+}
+
+// Concat returns a new open series with the points of b appended to the
+// points of a. When the last point of a is the same as the first point of b,
+// that point is only included once. If either series is empty then a copy of
+// the other is returned.
+func Concat(a, b Series, opts *IndexOptions) Series {
+	if a.Empty() || b.Empty() {
+		if a.Empty() {
+			a = b
+		}
+		series := makeSeries(seriesCopyPoints(a), false, a.Closed(), opts)
+		return &series
+	}
+	an, bn := a.NumPoints(), b.NumPoints()
+	points := make([]Point, 0, an+bn)
+	for i := 0; i < an; i++ {
+		points = append(points, a.PointAt(i))
+	}
+	start := 0
+	if a.PointAt(an-1) == b.PointAt(0) {
+		start = 1
+	}
+	for i := start; i < bn; i++ {
+		points = append(points, b.PointAt(i))
+	}
+	series := makeSeries(points, false, false, opts)
+	return &series
+}
+
+// Densify returns a new series where segments that are longer than maxSegLen
+// are split into equal parts that are no longer than maxSegLen. A closed
+// series remains closed. A maxSegLen of zero or less returns a copy of the
+// series.
+func Densify(series Series, maxSegLen float64, opts *IndexOptions) Series {
+	if maxSegLen <= 0 {
+		nseries := makeSeries(seriesCopyPoints(series), false,
+			series.Closed(), opts)
+		return &nseries
+	}
+	var points []Point
+	n := series.NumSegments()
+	for i := 0; i < n; i++ {
+		seg := series.SegmentAt(i)
+		points = append(points, seg.A)
+		parts := math.Ceil(seg.A.Distance(seg.B) / maxSegLen)
+		for j := 1.0; j < parts; j++ {
+			t := j / parts
+			points = append(points, Point{
+				X: seg.A.X + (seg.B.X-seg.A.X)*t,
+				Y: seg.A.Y + (seg.B.Y-seg.A.Y)*t,
+			})
+		}
+	}
+	numPoints := series.NumPoints()
+	if n == 0 {
+		points = seriesCopyPoints(series)
+	} else if !series.Closed() ||
+		series.PointAt(numPoints-1) == series.PointAt(0) {
+		points = append(points, series.PointAt(numPoints-1))
+	}
+	nseries := makeSeries(points, false, series.Closed(), opts)
+	return &nseries
+}
+
+// Resample returns a new series with n points that are placed at equal
+// distances along the series, starting with the first point and ending with
+// the last point. Unlike Densify, which limits the length of the segments,
+// the number of points is fixed. A closed series remains closed and has n
+// points around its perimeter, plus the first point repeated at the end.
+// An n less than 2, or less than 3 for a closed series, is raised to that
+// minimum. A series without segments returns a copy of the series.
+func Resample(series Series, n int, opts *IndexOptions) Series {
+	numSegs := series.NumSegments()
+	if numSegs == 0 {
+		nseries := makeSeries(seriesCopyPoints(series), false,
+			series.Closed(), opts)
+		return &nseries
+	}
+	closed := series.Closed()
+	parts := n - 1
+	if closed {
+		if n < 3 {
+			n = 3
+		}
+		parts = n
+	} else if n < 2 {
+		parts = 1
+	}
+	step := seriesLength(series) / float64(parts)
+	points := make([]Point, 0, parts+1)
+	var i int         // current segment
+	var start float64 // distance to the start of the current segment
+	seg := series.SegmentAt(0)
+	segLen := seg.A.Distance(seg.B)
+	for k := 0; k < parts; k++ {
+		dist := step * float64(k)
+		for i < numSegs-1 && start+segLen < dist {
+			start += segLen
+			i++
+			seg = series.SegmentAt(i)
+			segLen = seg.A.Distance(seg.B)
+		}
+		if segLen == 0 {
+			points = append(points, seg.A)
+		} else {
+			t := math.Min((dist-start)/segLen, 1)
+			points = append(points, seg.A.Lerp(seg.B, t))
+		}
+	}
+	if closed {
+		points = append(points, points[0])
+	} else {
+		points = append(points, series.PointAt(series.NumPoints()-1))
+	}
+	nseries := makeSeries(points, false, closed, opts)
+	return &nseries
+}
+
+// Snap returns a new series where each point is rounded to the nearest
+// multiple of gridSize. Consecutive points that become the same point are
+// reduced to one, which makes it useful for removing near duplicate points.
+// A closed series remains closed. A gridSize of zero or less returns a copy
+// of the series.
+func Snap(series Series, gridSize float64, opts *IndexOptions) Series {
+	points := seriesCopyPoints(series)
+	if gridSize > 0 {
+		var j int
+		for i, point := range points {
+			point.X = math.Round(point.X/gridSize) * gridSize
+			point.Y = math.Round(point.Y/gridSize) * gridSize
+			if i == 0 || point != points[j-1] {
+				points[j] = point
+				j++
+			}
+		}
+		points = points[:j]
+	}
+	nseries := makeSeries(points, false, series.Closed(), opts)
+	return &nseries
+}
+
+// RemoveDuplicatePoints returns a new series without the points that are
+// within tolerance of the previous point. For a closed series where the last
+// point is the same as the first, that closing point is preserved, and other
+// points near the end that are within tolerance of it are removed instead.
+// The new series is indexed in the same manner as the original series.
+func RemoveDuplicatePoints(series Series, tolerance float64) Series {
+	points := seriesCopyPoints(series)
+	n := len(points)
+	closing := series.Closed() && n > 1 && points[0] == points[n-1]
+	if closing {
+		n--
+	}
+	var j int
+	for i := 0; i < n; i++ {
+		if j == 0 || points[i].Distance(points[j-1]) > tolerance {
+			points[j] = points[i]
+			j++
+		}
+	}
+	if closing {
+		for j > 1 && points[j-1].Distance(points[0]) <= tolerance {
+			j--
+		}
+		points[j] = points[0]
+		j++
+	}
+	nseries := makeSeries(points[:j], false, series.Closed(),
+		seriesIndexOptions(series))
+	return &nseries
+}
+
+// seriesReverse returns a new series with the points in reverse order. The
+// new series is indexed in the same manner as the original series.
+func seriesReverse(series Series) Series {
+	n := series.NumPoints()
+	points := make([]Point, n)
+	for i := 0; i < n; i++ {
+		points[i] = series.PointAt(n - 1 - i)
+	}
+	nseries := makeSeries(points, false, series.Closed(), NoIndexing)
+	if index := series.Index(); len(index) > 0 {
+		opts := seriesIndexOptions(series)
+		nseries.indexKind, nseries.indexOpts = opts.Kind, *opts
+		nseries.buildIndex()
+	}
+	return &nseries
+}
+
+// EnsureClockwise returns a series with points that move clockwise. The same
+// series is returned when it's already clockwise, otherwise a new series is
+// returned with the points reversed.
+func EnsureClockwise(series Series) Series {
+	if series.Clockwise() {
+		return series
+	}
+	return seriesReverse(series)
+}
+
+// EnsureCounterClockwise returns a series with points that move
+// counter-clockwise. The same series is returned when it's already
+// counter-clockwise, otherwise a new series is returned with the points
+// reversed.
+func EnsureCounterClockwise(series Series) Series {
+	if !series.Clockwise() {
+		return series
+	}
+	return seriesReverse(series)
+}
+
+// CoordinatesClosed returns true if the first and last points of the series
+// are the same point. This is independent of the Closed flag that was set
+// when the series was created, which is useful for normalizing rings that
+// may or may not repeat their first point. An empty series is not closed.
+func CoordinatesClosed(series Series) bool {
+	n := series.NumPoints()
+	return n > 1 && series.PointAt(0) == series.PointAt(n-1)
+}
+
+// seriesLength returns the total length of all segments in the series.
+func seriesLength(series Series) float64 {
+	var length float64
+	n := series.NumSegments()
+	for i := 0; i < n; i++ {
+		seg := series.SegmentAt(i)
+		length += seg.A.Distance(seg.B)
+	}
+	return length
+}
+
+// seriesPointAtLength returns the point that is the distance along the
+// series, starting at the first point. The distance is clamped to the length
+// of the series.
+func seriesPointAtLength(series Series, dist float64) Point {
+	n := series.NumSegments()
+	if n == 0 {
+		if series.NumPoints() > 0 {
+			return series.PointAt(0)
+		}
+		return Point{}
+	}
+	for i := 0; i < n; i++ {
+		seg := series.SegmentAt(i)
+		segLen := seg.A.Distance(seg.B)
+		if dist <= segLen {
+			if dist <= 0 || segLen == 0 {
+				return seg.A
+			}
+			t := dist / segLen
+			return Point{
+				X: seg.A.X + (seg.B.X-seg.A.X)*t,
+				Y: seg.A.Y + (seg.B.Y-seg.A.Y)*t,
+			}
+		}
+		dist -= segLen
+	}
+	return series.SegmentAt(n - 1).B
+}
+
+// NearestSegment is a segment result from a nearest segments query.
+type NearestSegment struct {
+	Seg  Segment
+	Idx  int
+	Dist float64
+}
+
+// KNearestSegments returns the k nearest segments in the series, ordered from
+// nearest to farthest. The distance calculations are performed by the
+// distToRect and distToSegment functions, just like DistanceToSeries.
+// All segments are returned when k is larger than the number of segments.
+func KNearestSegments(
+	series Series, k int,
+	distToRect func(rect Rect) float64,
+	distToSegment func(seg Segment) float64,
+) []NearestSegment {
+	if k <= 0 {
+		return nil
+	}
+	var results []NearestSegment
+	base := seriesBase(series)
+	if base == nil || len(base.index) == 0 {
+		n := series.NumSegments()
+		for i := 0; i < n; i++ {
+			seg := series.SegmentAt(i)
+			results = append(results, NearestSegment{seg, i, distToSegment(seg)})
+		}
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].Dist < results[j].Dist
+		})
+		if len(results) > k {
+			results = results[:k]
+		}
+	} else {
+		data := base.index
+		n := binary.LittleEndian.Uint32(data[1:])
+		data = data[:n:n]
+		qCompressNearest(data, 5, base, base.rect, distToRect, distToSegment,
+			func(seg Segment, idx int, dist float64) bool {
+				results = append(results, NearestSegment{seg, idx, dist})
+				return len(results) < k
+			},
+		)
+	}
+	return results
+}
+
+// KNearestByMidpoint returns the indexes of the k segments whose midpoints
+// are nearest to the point, ordered from nearest to farthest.
+func KNearestByMidpoint(series Series, point Point, k int) []int {
+	// A segment rect always contains the segment midpoint, making the
+	// distance to the rect a lower bound for the distance to the midpoint.
+	results := KNearestSegments(series, k,
+		func(rect Rect) float64 {
+			return rect.DistanceToPoint(point)
+		},
+		func(seg Segment) float64 {
+			return point.Distance(Point{
+				X: (seg.A.X + seg.B.X) / 2,
+				Y: (seg.A.Y + seg.B.Y) / 2,
+			})
+		},
+	)
+	idxs := make([]int, len(results))
+	for i, res := range results {
+		idxs[i] = res.Idx
+	}
+	return idxs
+}
+
+// SegmentIndexAt returns the index of the segment that the point is on,
+// where the point may be up to tolerance away from the segment. This is
+// useful for finding the edge that was clicked on in an editor. The index is
+// used to find the candidate segments, and when more than one segment is
+// close enough, such as near a vertex, the lowest index is returned.
+// Returns false if no segment is within tolerance of the point.
+func SegmentIndexAt(series Series, point Point, tolerance float64) (int,
+	bool) {
+	if tolerance < 0 {
+		return -1, false
+	}
+	rect := Rect{
+		Min: Point{point.X - tolerance, point.Y - tolerance},
+		Max: Point{point.X + tolerance, point.Y + tolerance},
+	}
+	found := -1
+	series.Search(rect, func(seg Segment, idx int) bool {
+		if (found == -1 || idx < found) && seg.Distance(point) <= tolerance {
+			found = idx
+		}
+		return true
+	})
+	return found, found != -1
+}
+
+// The kinds of snap that are returned by SnapPoint.
+const (
+	SnapVertex = 1 // snapped to a point of the series
+	SnapEdge   = 2 // snapped onto a segment of the series
+)
+
+// SnapPoint returns the point moved onto the series, for welding a point
+// that is being dragged in an editor. A point of the series that is within
+// tolerance is preferred, in which case kind is SnapVertex and idx is the
+// index of that point. Otherwise the point is moved to the nearest point on
+// the nearest segment within tolerance, in which case kind is SnapEdge and
+// idx is the index of the segment. The index is used to find the candidate
+// segments, and ties go to the lowest index. Returns false if nothing is
+// within tolerance of the point.
+func SnapPoint(series Series, point Point, tolerance float64) (
+	snapped Point, kind int, idx int, ok bool) {
+	if tolerance < 0 {
+		return point, 0, -1, false
+	}
+	rect := Rect{
+		Min: Point{point.X - tolerance, point.Y - tolerance},
+		Max: Point{point.X + tolerance, point.Y + tolerance},
+	}
+	numPoints := series.NumPoints()
+	vidx, eidx := -1, -1
+	var vdist, edist float64
+	var vpoint, epoint Point
+	series.Search(rect, func(seg Segment, i int) bool {
+		for j, vertex := range [...]Point{seg.A, seg.B} {
+			// the end of the last segment may wrap around to the first point
+			pidx := (i + j) % numPoints
+			dist := vertex.Distance(point)
+			if dist <= tolerance && (vidx == -1 || dist < vdist ||
+				(dist == vdist && pidx < vidx)) {
+				vidx, vdist, vpoint = pidx, dist, vertex
+			}
+		}
+		closest := seg.ClosestPoint(point)
+		dist := closest.Distance(point)
+		if dist <= tolerance && (eidx == -1 || dist < edist ||
+			(dist == edist && i < eidx)) {
+			eidx, edist, epoint = i, dist, closest
+		}
+		return true
+	})
+	if vidx != -1 {
+		return vpoint, SnapVertex, vidx, true
+	}
+	if eidx != -1 {
+		return epoint, SnapEdge, eidx, true
+	}
+	return point, 0, -1, false
+}
+
+// ForEachSegmentReverse iterates over the segments of the series from the
+// last segment to the first, along with their indexes. Each segment keeps
+// its forward direction, from A to B, and only the order of the segments is
+// reversed. Returning false from the iter function stops the iteration.
+func ForEachSegmentReverse(series Series,
+	iter func(seg Segment, index int) bool) {
+	for i := series.NumSegments() - 1; i >= 0; i-- {
+		if !iter(series.SegmentAt(i), i) {
+			return
+		}
+	}
+}
+
+// PointsInRect iterates over the points of the series that are inside of
+// the rectangle, including its edges, along with their indexes. The index of
+// the series is used by searching for the segments that intersect the
+// rectangle and testing their endpoints. Each point is the first point of a
+// segment, except for the last point of an open or explicitly closed
+// series, so every point is visited once. Points are visited in no
+// particular order, and returning false from the iter function stops the
+// iteration.
+func PointsInRect(series Series, rect Rect,
+	iter func(point Point, index int) bool) {
+	numPoints := series.NumPoints()
+	numSegs := series.NumSegments()
+	if numSegs == 0 {
+		for i := 0; i < numPoints; i++ {
+			point := series.PointAt(i)
+			if rect.ContainsPoint(point) && !iter(point, i) {
+				return
+			}
+		}
+		return
+	}
+	// the last point does not start a segment
+	lastEnd := numSegs == numPoints-1
+	series.Search(rect, func(seg Segment, idx int) bool {
+		if rect.ContainsPoint(seg.A) && !iter(seg.A, idx) {
+			return false
+		}
+		if lastEnd && idx == numSegs-1 && rect.ContainsPoint(seg.B) &&
+			!iter(seg.B, idx+1) {
+			return false
+		}
+		return true
+	})
+}
+
+// SegmentsWithinRadius iterates over all segments in the series that are
+// within the radius of the point. Segments that are exactly at the radius are
+// included. Segments are visited in no particular order, and returning false
+// from the iter function stops the iteration.
+func SegmentsWithinRadius(
+	series Series, point Point, radius float64,
+	iter func(seg Segment, idx int, dist float64) bool,
+) {
+	if radius < 0 {
+		return
+	}
+	base := seriesBase(series)
+	if base == nil || len(base.index) == 0 {
+		rect := Rect{
+			Min: Point{point.X - radius, point.Y - radius},
+			Max: Point{point.X + radius, point.Y + radius},
+		}
+		series.Search(rect, func(seg Segment, idx int) bool {
+			if dist := seg.Distance(point); dist <= radius {
+				return iter(seg, idx, dist)
+			}
+			return true
+		})
+		return
+	}
+	data := base.index
+	n := binary.LittleEndian.Uint32(data[1:])
+	data = data[:n:n]
+	qCompressNearest(data, 5, base, base.rect,
+		func(rect Rect) float64 {
+			return rect.DistanceToPoint(point)
+		},
+		func(seg Segment) float64 {
+			return seg.Distance(point)
+		},
+		func(seg Segment, idx int, dist float64) bool {
+			if dist > radius {
+				// all remaining segments are farther away
+				return false
+			}
+			return iter(seg, idx, dist)
+		},
+	)
+}
+
 func (series *baseSeries) NumSegments() int {
 	if series.closed {
 		if len(series.points) < 3 {
@@ -291,7 +1289,7 @@ func processPoints(points []Point, closed bool) (
 		}
 
 		// process the clockwise detection
-		cwc += (b.X - a.X) * (b.Y + a.Y)
+		cwc += Segment{a, b}.SignedAreaContribution()
 
 		// process the convex calculation
 		if concave {
@@ -329,16 +1327,46 @@ func (series *baseSeries) setCompressed(data []byte) {
 	series.index = smaller
 }
 
+// buildIndex builds the index using the options that the series was created
+// with, so a rebuilt index is the same as the original.
 func (series *baseSeries) buildIndex() {
+	series.buildIndexOpts(&series.indexOpts)
+}
+
+// buildIndexOpts builds the index using the BulkLoad, MaxNodeItems, and
+// MaxDepth options. With BulkLoad, the segments are inserted in the order of
+// the Hilbert curve values of their rectangle centers.
+func (series *baseSeries) buildIndexOpts(opts *IndexOptions) {
 	if series.index != nil {
 		// already built
 		return
 	}
-	root := new(qNode)
+	limits := qLimitsFromOpts(opts)
 	n := series.NumSegments()
-	for i := 0; i < n; i++ {
+	items := make([]int, n)
+	for i := range items {
+		items[i] = i
+	}
+	if opts != nil && opts.BulkLoad {
+		const order = 16
+		values := make([]uint64, n)
+		for i := 0; i < n; i++ {
+			rect := series.SegmentAt(i).Rect()
+			center := Point{
+				(rect.Min.X + rect.Max.X) / 2,
+				(rect.Min.Y + rect.Max.Y) / 2,
+			}
+			x, y := curveGridXY(center, series.rect, order)
+			values[i] = hilbertXYToIndex(x, y, order)
+		}
+		sort.Slice(items, func(i, j int) bool {
+			return values[items[i]] < values[items[j]]
+		})
+	}
+	root := new(qNode)
+	for _, i := range items {
 		seg := series.SegmentAt(i)
-		root.insert(series, series.rect, seg.Rect(), i, 0)
+		root.insert(series, series.rect, seg.Rect(), i, 0, limits)
 	}
 	series.setCompressed(
 		root.compress([]byte{byte(series.indexKind), 0, 0, 0, 0}),
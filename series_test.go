@@ -5,9 +5,14 @@
 package geometry
 
 import (
+	"fmt"
 	"math"
+	"math/rand"
 	"reflect"
+	"sort"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestIndexKind(t *testing.T) {
@@ -394,21 +399,78 @@ func TestSeriesMove(t *testing.T) {
 	}
 }
 
-func distPointToRect(p Point, r Rect) float64 {
-	if r.ContainsPoint(p) {
-		return 0
-	}
-	var dist float64
-	var squared float64
-	squared = math.Max(p.X, r.Min.X) - math.Min(p.X, r.Max.X)
-	if squared > 0 {
-		dist += squared * squared
+func TestSeriesMoveFlags(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, shape := range [][]Point{RI, octagon, AZ, u1, v1, nil} {
+		for _, closed := range []bool{true, false} {
+			series := makeSeries(shape, true, closed, DefaultIndexOptions)
+			dx, dy := rng.Float64()*200-100, rng.Float64()*200-100
+			moved := series.Move(dx, dy).(*baseSeries)
+			fresh := makeSeries(moved.points, true, closed,
+				DefaultIndexOptions)
+			expect(t, moved.Convex() == fresh.Convex())
+			expect(t, moved.Clockwise() == fresh.Clockwise())
+			expect(t, moved.Closed() == fresh.Closed())
+			expect(t, moved.Rect() == fresh.Rect())
+			expect(t, (moved.Index() == nil) == (series.Index() == nil))
+			// the index finds the same segments as a fresh index
+			for i := 0; i < 100; i++ {
+				x := moved.rect.Min.X +
+					rng.Float64()*(moved.rect.Max.X-moved.rect.Min.X)
+				y := moved.rect.Min.Y +
+					rng.Float64()*(moved.rect.Max.Y-moved.rect.Min.Y)
+				rect := R(x, y, x+rng.Float64()*2, y+rng.Float64()*2)
+				var a, b []int
+				moved.Search(rect, func(seg Segment, idx int) bool {
+					a = append(a, idx)
+					return true
+				})
+				fresh.Search(rect, func(seg Segment, idx int) bool {
+					b = append(b, idx)
+					return true
+				})
+				sort.Ints(a)
+				sort.Ints(b)
+				expect(t, reflect.DeepEqual(a, b))
+			}
+		}
 	}
-	squared = math.Max(p.Y, r.Min.Y) - math.Min(p.Y, r.Max.Y)
-	if squared > 0 {
-		dist += squared * squared
+}
+
+func BenchmarkSeriesMove(b *testing.B) {
+	series := makeSeries(AZ, true, true, DefaultIndexOptions)
+	b.Run("Move", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			series.Move(1, 1)
+		}
+	})
+	b.Run("Rebuild", func(b *testing.B) {
+		b.ReportAllocs()
+		points := make([]Point, len(series.points))
+		for i := 0; i < b.N; i++ {
+			for j, point := range series.points {
+				points[j] = point.Move(1, 1)
+			}
+			makeSeries(points, false, true, DefaultIndexOptions)
+		}
+	})
+}
+
+func TestSeriesCoverageCounts(t *testing.T) {
+	series := makeSeries(octagon, true, true, DefaultIndexOptions)
+	for i := 0; i < 2; i++ {
+		inside, crossing := series.CoverageCounts(R(-1, -1, 5, 11))
+		expect(t, inside == 3 && crossing == 2)
+		inside, crossing = series.CoverageCounts(R(-1, -1, 11, 11))
+		expect(t, inside == 8 && crossing == 0)
+		// bounding boxes overlap but the diagonal segment passes above
+		inside, crossing = series.CoverageCounts(R(9, 0, 10, 1))
+		expect(t, inside == 0 && crossing == 0)
+		inside, crossing = series.CoverageCounts(R(20, 20, 30, 30))
+		expect(t, inside == 0 && crossing == 0)
+		series.buildIndex()
 	}
-	return math.Sqrt(dist)
 }
 
 func distPointToPoint(a, b Point) float64 {
@@ -435,7 +497,7 @@ func TestDistanceToSeries(t *testing.T) {
 		_, _, dist := DistanceToSeries(
 			poly.Exterior,
 			func(rect Rect) float64 {
-				return distPointToRect(p, rect)
+				return rect.DistanceToPoint(p)
 			},
 			func(seg Segment) float64 {
 				return distPointToSegment(p, seg)
@@ -444,3 +506,1219 @@ func TestDistanceToSeries(t *testing.T) {
 		expect(t, math.Abs(dist-1.866511) < 0.000001)
 	})
 }
+
+func TestSeriesLength(t *testing.T) {
+	line := L(P(0, 0), P(3, 4), P(3, 10))
+	expect(t, seriesLength(line) == 11)
+	expect(t, seriesPointAtLength(line, -1) == P(0, 0))
+	expect(t, seriesPointAtLength(line, 0) == P(0, 0))
+	expect(t, seriesPointAtLength(line, 2.5) == P(1.5, 2))
+	expect(t, seriesPointAtLength(line, 5) == P(3, 4))
+	expect(t, seriesPointAtLength(line, 8) == P(3, 7))
+	expect(t, seriesPointAtLength(line, 20) == P(3, 10))
+	ring := makeSeries([]Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}}, true, true, nil)
+	expect(t, seriesLength(&ring) == 40)
+	expect(t, seriesPointAtLength(&ring, 35) == P(0, 5))
+	expect(t, seriesPointAtLength(&baseSeries{}, 5) == P(0, 0))
+}
+
+func TestIsSimple(t *testing.T) {
+	figure8 := []Point{{0, 0}, {10, 10}, {10, 0}, {0, 10}, {0, 0}}
+	touching := []Point{
+		{0, 0}, {10, 0}, {5, 5}, {10, 10}, {0, 10}, {5, 5}, {0, 0},
+	}
+	spike := []Point{{0, 0}, {10, 0}, {10, 10}, {10, 5}, {0, 10}, {0, 0}}
+	for _, opts := range []*IndexOptions{
+		NoIndexing, {Kind: QuadTree, MinPoints: 1},
+	} {
+		ring := func(points []Point) Series {
+			series := makeSeries(points, true, true, opts)
+			return &series
+		}
+		expect(t, IsSimple(ring(octagon)))
+		expect(t, IsSimple(ring(concave1)))
+		expect(t, IsSimple(ring(bowtie)))
+		expect(t, IsSimple(ring(octagon[:len(octagon)-1])))
+		expect(t, IsSimple(ring(AZ)))
+		expect(t, !IsSimple(ring(figure8)))
+		// touching at a single vertex is not simple
+		expect(t, !IsSimple(ring(touching)))
+		// adjacent segments that fold back over each other
+		expect(t, !IsSimple(ring(spike)))
+
+		expect(t, IsSimple(NewLine(u1, opts)))
+		expect(t, IsSimple(NewLine(v1, opts)))
+		expect(t, IsSimple(NewLine(octagon, opts)))
+		expect(t, !IsSimple(NewLine(figure8, opts)))
+		expect(t, !IsSimple(NewLine([]Point{{0, 0}, {10, 0}, {5, 0}}, opts)))
+		expect(t, IsSimple(NewLine([]Point{{0, 0}, {10, 0}}, opts)))
+		expect(t, IsSimple(NewLine(nil, opts)))
+	}
+	expect(t, IsSimple(R(0, 0, 10, 10)))
+}
+
+func TestSeriesRotatedBounds(t *testing.T) {
+	for _, shape := range [][]Point{octagon, concave1, bowtie, AZ} {
+		series := makeSeries(shape, true, true, DefaultIndexOptions)
+		origin := series.Rect().Center().Move(3, -2)
+		for _, rad := range []float64{0, 0.3, math.Pi / 4, math.Pi / 2, 2, math.Pi} {
+			loose := series.RotatedBounds(origin, rad, false)
+			tight := series.RotatedBounds(origin, rad, true)
+			rotated := series.Transform(Translate(-origin.X, -origin.Y).
+				Multiply(Rotation(rad)).
+				Multiply(Translate(origin.X, origin.Y)))
+			expect(t, tight == rotated.Rect())
+			expect(t, loose.ContainsRect(tight))
+		}
+	}
+	series := makeSeries(octagon, true, true, DefaultIndexOptions)
+	bounds := series.RotatedBounds(P(5, 5), math.Pi/2, false)
+	expect(t, pointsNear(bounds.Min, P(0, 0), 1e-12))
+	expect(t, pointsNear(bounds.Max, P(10, 10), 1e-12))
+	bounds = series.RotatedBounds(P(5, 5), math.Pi/4, false)
+	expect(t, pointsNear(bounds.Min, P(5-5*math.Sqrt2, 5-5*math.Sqrt2), 1e-12))
+	expect(t, (&baseSeries{}).RotatedBounds(P(5, 5), 1, false) == Rect{})
+	expect(t, (&baseSeries{}).RotatedBounds(P(5, 5), 1, true) == Rect{})
+}
+
+func TestSeriesWithinRect(t *testing.T) {
+	series := makeSeries(octagon, true, true, DefaultIndexOptions)
+	expect(t, series.WithinRect(R(-1, -1, 11, 11)))
+	expect(t, series.WithinRect(R(0, 0, 10, 10)))
+	expect(t, !series.WithinRect(R(1, -1, 11, 11)))
+	expect(t, !series.WithinRect(R(20, 20, 30, 30)))
+	expect(t, Series(R(0, 0, 10, 10)).WithinRect(R(0, 0, 10, 10)))
+	expect(t, !Series(R(0, 0, 10, 10)).WithinRect(R(0, 0, 5, 10)))
+}
+
+func TestSeriesCountSearchBatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	series := makeSeries(AZ, true, true, DefaultIndexOptions)
+	bounds := series.Rect()
+	rects := make([]Rect, 500)
+	for i := range rects {
+		x := bounds.Min.X + rng.Float64()*(bounds.Max.X-bounds.Min.X+2) - 1
+		y := bounds.Min.Y + rng.Float64()*(bounds.Max.Y-bounds.Min.Y+2) - 1
+		w, h := rng.Float64()*2, rng.Float64()*2
+		rects[i] = R(x, y, x+w, y+h)
+	}
+	rects = append(rects, bounds, R(0, 0, 1, 1))
+	for i := 0; i < 2; i++ {
+		counts := series.CountSearchBatch(rects)
+		expect(t, len(counts) == len(rects))
+		for j, rect := range rects {
+			var count int
+			series.Search(rect, func(seg Segment, idx int) bool {
+				count++
+				return true
+			})
+			expect(t, counts[j] == count)
+		}
+		expect(t, counts[len(counts)-2] == series.NumSegments())
+		expect(t, counts[len(counts)-1] == 0)
+		series.clearIndex()
+	}
+	expect(t, len(series.CountSearchBatch(nil)) == 0)
+}
+
+func TestSplitAt(t *testing.T) {
+	line := NewLine(AZ[:500], DefaultIndexOptions)
+	for _, index := range []int{0, 1, 63, 250, 498, 499} {
+		a, b := SplitAt(line, index)
+		expect(t, a.NumPoints()+b.NumPoints() == line.NumPoints()+1)
+		expect(t, !a.Closed() && !b.Closed())
+		expect(t, a.PointAt(a.NumPoints()-1) == line.PointAt(index))
+		expect(t, b.PointAt(0) == line.PointAt(index))
+		for i := 0; i < a.NumPoints(); i++ {
+			expect(t, a.PointAt(i) == line.PointAt(i))
+		}
+		for i := 0; i < b.NumPoints(); i++ {
+			expect(t, b.PointAt(i) == line.PointAt(index+i))
+		}
+		expect(t, (a.Index() != nil) == (a.NumPoints() >= 64))
+		expect(t, (b.Index() != nil) == (b.NumPoints() >= 64))
+	}
+	a, b := SplitAt(NewLine(AZ[:500], NoIndexing), 250)
+	expect(t, a.Index() == nil && b.Index() == nil)
+
+	// closed series are opened at the index
+	for _, points := range [][]Point{octagon, octagon[:len(octagon)-1]} {
+		ring := newRing(points, DefaultIndexOptions)
+		a, b := SplitAt(ring, 2)
+		expect(t, b.Empty())
+		expect(t, !a.Closed())
+		expect(t, a.NumPoints() == len(octagon))
+		expect(t, a.PointAt(0) == octagon[2])
+		expect(t, a.PointAt(a.NumPoints()-1) == octagon[2])
+		expect(t, a.NumSegments() == ring.NumSegments())
+		expect(t, seriesLength(a) == seriesLength(ring))
+	}
+
+	defer func() { expect(t, recover() != nil) }()
+	SplitAt(line, 500)
+}
+
+func TestConcat(t *testing.T) {
+	a := L(P(0, 0), P(10, 0), P(10, 10))
+	b := L(P(10, 10), P(0, 10), P(0, 20))
+	c := L(P(10, 10.000001), P(0, 10), P(0, 20))
+	ab := Concat(a, b, DefaultIndexOptions)
+	expect(t, ab.NumPoints() == a.NumPoints()+b.NumPoints()-1)
+	expect(t, ab.NumSegments() == a.NumSegments()+b.NumSegments())
+	expect(t, !ab.Closed())
+	ac := Concat(a, c, DefaultIndexOptions)
+	expect(t, ac.NumPoints() == a.NumPoints()+c.NumPoints())
+	for i := 0; i < a.NumPoints(); i++ {
+		expect(t, ac.PointAt(i) == a.PointAt(i))
+	}
+	for i := 0; i < c.NumPoints(); i++ {
+		expect(t, ac.PointAt(a.NumPoints()+i) == c.PointAt(i))
+	}
+	big := Concat(NewLine(AZ[:40], nil), NewLine(AZ[39:80], nil), nil)
+	expect(t, big.NumPoints() == 80)
+	expect(t, big.Index() != nil)
+
+	empty := L()
+	expect(t, Concat(empty, b, nil).NumPoints() == b.NumPoints())
+	expect(t, Concat(a, empty, nil).NumPoints() == a.NumPoints())
+	ring := newRing(octagon, nil)
+	expect(t, Concat(empty, ring, nil).Closed())
+	expect(t, Concat(empty, empty, nil).NumPoints() == 0)
+}
+
+func TestKNearestSegments(t *testing.T) {
+	p := P(-111.1, 33.3)
+	distToRect := func(rect Rect) float64 { return rect.DistanceToPoint(p) }
+	distToSegment := func(seg Segment) float64 { return distPointToSegment(p, seg) }
+	var all []NearestSegment
+	dualPolyTest(t, AZ, nil, func(t *testing.T, poly *Poly) {
+		n := poly.Exterior.NumSegments()
+		results := KNearestSegments(poly.Exterior, 25, distToRect, distToSegment)
+		expect(t, len(results) == 25)
+		for i, res := range results {
+			expect(t, res.Seg == poly.Exterior.SegmentAt(res.Idx))
+			expect(t, res.Dist == distToSegment(res.Seg))
+			if i > 0 {
+				expect(t, results[i-1].Dist <= res.Dist)
+			}
+		}
+		seg, idx, dist := DistanceToSeries(poly.Exterior, distToRect, distToSegment)
+		results = KNearestSegments(poly.Exterior, 1, distToRect, distToSegment)
+		expect(t, len(results) == 1)
+		expect(t, results[0] == NearestSegment{seg, idx, dist})
+
+		results = KNearestSegments(poly.Exterior, n+100, distToRect, distToSegment)
+		expect(t, len(results) == n)
+		if all == nil {
+			all = results
+		} else {
+			// both the indexed and non-indexed results must match
+			for i := range all {
+				expect(t, all[i].Dist == results[i].Dist)
+			}
+		}
+		expect(t, KNearestSegments(poly.Exterior, 0, distToRect, distToSegment) == nil)
+	})
+	results := KNearestSegments(R(0, 0, 10, 10), 2,
+		func(rect Rect) float64 { return rect.DistanceToPoint(P(5, -1)) },
+		func(seg Segment) float64 { return distPointToSegment(P(5, -1), seg) },
+	)
+	expect(t, len(results) == 2)
+	expect(t, results[0].Idx == 0 && results[0].Dist == 1)
+}
+
+func TestKNearestByMidpoint(t *testing.T) {
+	p := P(-111.1, 33.3)
+	dualPolyTest(t, AZ, nil, func(t *testing.T, poly *Poly) {
+		n := poly.Exterior.NumSegments()
+		dists := make([]float64, n)
+		idxs := make([]int, n)
+		for i := 0; i < n; i++ {
+			seg := poly.Exterior.SegmentAt(i)
+			mid := P((seg.A.X+seg.B.X)/2, (seg.A.Y+seg.B.Y)/2)
+			dists[i] = p.Distance(mid)
+			idxs[i] = i
+		}
+		sort.SliceStable(idxs, func(i, j int) bool {
+			return dists[idxs[i]] < dists[idxs[j]]
+		})
+		for _, k := range []int{1, 10, 100, n} {
+			results := KNearestByMidpoint(poly.Exterior, p, k)
+			expect(t, len(results) == k)
+			for i := range results {
+				expect(t, dists[results[i]] == dists[idxs[i]])
+			}
+		}
+	})
+	expect(t, len(KNearestByMidpoint(R(0, 0, 10, 10), P(5, 11), 1)) == 1)
+	expect(t, KNearestByMidpoint(R(0, 0, 10, 10), P(5, 11), 1)[0] == 2)
+}
+
+func TestSeriesBaseLine(t *testing.T) {
+	// an indexed line uses its index the same way as a ring
+	p := P(-111.1, 33.3)
+	line := NewLine(AZ, DefaultIndexOptions)
+	plain := NewLine(AZ, NoIndexing)
+	expect(t, seriesBase(line) == &line.baseSeries)
+	distToRect := func(rect Rect) float64 { return rect.DistanceToPoint(p) }
+	distToSegment := func(seg Segment) float64 {
+		return distPointToSegment(p, seg)
+	}
+	a := KNearestSegments(line, 20, distToRect, distToSegment)
+	b := KNearestSegments(plain, 20, distToRect, distToSegment)
+	expect(t, len(a) == 20 && len(b) == 20)
+	for i := range a {
+		expect(t, a[i].Dist == b[i].Dist)
+	}
+	// the index visits the segments from nearest to farthest
+	var dists []float64
+	SegmentsWithinRadius(line, p, 3, func(seg Segment, idx int,
+		dist float64) bool {
+		dists = append(dists, dist)
+		return true
+	})
+	expect(t, len(dists) > 1 && sort.Float64sAreSorted(dists))
+	var count int
+	SegmentsWithinRadius(plain, p, 3, func(seg Segment, idx int,
+		dist float64) bool {
+		count++
+		return true
+	})
+	expect(t, count == len(dists))
+}
+
+func TestSegmentsWithinRadius(t *testing.T) {
+	p := P(-111.1, 33.3)
+	dualPolyTest(t, AZ, nil, func(t *testing.T, poly *Poly) {
+		for _, radius := range []float64{0, 1.5, 2, 3, 10} {
+			found := make(map[int]bool)
+			SegmentsWithinRadius(poly.Exterior, p, radius,
+				func(seg Segment, idx int, dist float64) bool {
+					expect(t, seg == poly.Exterior.SegmentAt(idx))
+					expect(t, dist <= radius && dist == seg.Distance(p))
+					expect(t, !found[idx])
+					found[idx] = true
+					return true
+				},
+			)
+			n := poly.Exterior.NumSegments()
+			for i := 0; i < n; i++ {
+				inside := poly.Exterior.SegmentAt(i).Distance(p) <= radius
+				expect(t, inside == found[i])
+			}
+		}
+		var count int
+		SegmentsWithinRadius(poly.Exterior, p, 10,
+			func(seg Segment, idx int, dist float64) bool {
+				count++
+				return count < 5
+			},
+		)
+		expect(t, count == 5)
+	})
+	// exactly at the radius
+	var count int
+	SegmentsWithinRadius(R(0, 0, 10, 10), P(5, 15), 5,
+		func(seg Segment, idx int, dist float64) bool {
+			expect(t, idx == 2 && dist == 5)
+			count++
+			return true
+		},
+	)
+	expect(t, count == 1)
+	SegmentsWithinRadius(R(0, 0, 10, 10), P(5, 15), 4.999,
+		func(seg Segment, idx int, dist float64) bool {
+			t.Fatal("segment outside of radius")
+			return true
+		},
+	)
+}
+
+func TestSeriesShouldIndex(t *testing.T) {
+	triangle := makeSeries([]Point{{0, 0}, {10, 0}, {5, 10}}, true, true, nil)
+	expect(t, !triangle.ShouldIndex())
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	coastline := make([]Point, 10000)
+	for i := 1; i < len(coastline); i++ {
+		coastline[i].X = coastline[i-1].X + rng.Float64() - 0.5
+		coastline[i].Y = coastline[i-1].Y + rng.Float64() - 0.5
+	}
+	series := makeSeries(coastline, true, false, NoIndexing)
+	expect(t, series.ShouldIndex())
+	series = makeSeries(AZ, true, true, NoIndexing)
+	expect(t, series.ShouldIndex())
+	// every segment crosses the entire shape
+	var star []Point
+	for i := 0; i < 101; i++ {
+		angle := float64(i) * math.Pi * 0.99
+		star = append(star, P(math.Cos(angle)*10, math.Sin(angle)*10))
+	}
+	series = makeSeries(star, true, true, NoIndexing)
+	expect(t, !series.ShouldIndex())
+	series = makeSeries(make([]Point, 100), true, false, NoIndexing)
+	expect(t, !series.ShouldIndex())
+}
+
+func TestDensify(t *testing.T) {
+	for _, series := range []Series{
+		NewLine(u1, nil),
+		NewLine(v1, nil),
+		newRing(octagon, nil),
+		newRing(octagon[:len(octagon)-1], nil),
+		newRing(AZ, nil),
+		R(0, 0, 10, 5),
+	} {
+		for _, maxSegLen := range []float64{0.01, 0.5, 1, 3, 100} {
+			dense := Densify(series, maxSegLen, nil)
+			expect(t, dense.Closed() == series.Closed())
+			expect(t, dense.NumPoints() >= series.NumPoints())
+			n := dense.NumSegments()
+			for i := 0; i < n; i++ {
+				seg := dense.SegmentAt(i)
+				expect(t, seg.A.Distance(seg.B) <= maxSegLen+1e-9)
+			}
+			expect(t, math.Abs(seriesLength(dense)-seriesLength(series)) < 1e-9)
+			expect(t, dense.PointAt(0) == series.PointAt(0))
+		}
+		same := Densify(series, 0, nil)
+		expect(t, same.NumPoints() == series.NumPoints())
+	}
+	dense := Densify(NewLine([]Point{{0, 0}, {10, 0}}, nil), 2.5, nil)
+	expect(t, dense.NumPoints() == 5)
+	expect(t, dense.PointAt(2) == P(5, 0))
+	expect(t, Densify(NewLine(nil, nil), 1, nil).NumPoints() == 0)
+	expect(t, Densify(NewLine([]Point{{1, 1}}, nil), 1, nil).NumPoints() == 1)
+}
+
+func TestResample(t *testing.T) {
+	for _, series := range []Series{
+		NewLine(u1, nil),
+		NewLine(v1, nil),
+		newRing(octagon, nil),
+		newRing(octagon[:len(octagon)-1], nil),
+		newRing(AZ, nil),
+		R(0, 0, 10, 5),
+	} {
+		for _, n := range []int{3, 10, 57, 500} {
+			res := Resample(series, n, nil)
+			expect(t, res.Closed() == series.Closed())
+			if series.Closed() {
+				expect(t, res.NumPoints() == n+1)
+				expect(t, res.PointAt(n) == res.PointAt(0))
+			} else {
+				expect(t, res.NumPoints() == n)
+				expect(t, res.PointAt(n-1) ==
+					series.PointAt(series.NumPoints()-1))
+			}
+			expect(t, res.PointAt(0) == series.PointAt(0))
+			// every point is on the original series
+			for i := 0; i < res.NumPoints(); i++ {
+				_, _, dist := DistanceToSeriesMetric(series,
+					EuclideanMetric(res.PointAt(i)))
+				expect(t, dist < 1e-9)
+			}
+			// equal spacing along the original, which is never shorter
+			// than the straight segments between the resampled points
+			step := seriesLength(series) / float64(res.NumSegments())
+			for i := 0; i < res.NumSegments(); i++ {
+				seg := res.SegmentAt(i)
+				expect(t, seg.A.Distance(seg.B) <= step+1e-9)
+			}
+			expect(t, seriesLength(res) <= seriesLength(series)+1e-9)
+		}
+	}
+	res := Resample(NewLine([]Point{{0, 0}, {10, 0}, {10, 10}}, nil), 5, nil)
+	expect(t, res.NumPoints() == 5)
+	expect(t, res.PointAt(1) == P(5, 0))
+	expect(t, res.PointAt(2) == P(10, 0))
+	expect(t, res.PointAt(3) == P(10, 5))
+	expect(t, res.PointAt(4) == P(10, 10))
+	// minimum counts
+	expect(t, Resample(NewLine(u1, nil), 0, nil).NumPoints() == 2)
+	expect(t, Resample(newRing(octagon, nil), 1, nil).NumPoints() == 4)
+	// no segments
+	expect(t, Resample(NewLine(nil, nil), 5, nil).NumPoints() == 0)
+	expect(t, Resample(NewLine([]Point{{1, 1}}, nil), 5, nil).NumPoints() == 1)
+	// zero length
+	res = Resample(NewLine([]Point{{1, 1}, {1, 1}}, nil), 4, nil)
+	expect(t, res.NumPoints() == 4)
+	expect(t, res.PointAt(2) == P(1, 1))
+}
+
+func TestSnap(t *testing.T) {
+	line := NewLine([]Point{{0, 0}, {0.1, 0.2}, {0.9, 1.1}, {1.05, 0.95},
+		{2.2, 1.4}, {2.4, 0.6}}, nil)
+	snapped := Snap(line, 1, nil)
+	expect(t, !snapped.Closed())
+	expect(t, snapped.NumPoints() == 3)
+	expect(t, snapped.PointAt(0) == P(0, 0))
+	expect(t, snapped.PointAt(1) == P(1, 1))
+	expect(t, snapped.PointAt(2) == P(2, 1))
+	snapped = Snap(line, 0.5, nil)
+	expect(t, snapped.NumPoints() == 4)
+	expect(t, snapped.PointAt(2) == P(2, 1.5))
+
+	ring := newRing([]Point{{0.1, 0.1}, {9.9, 0.2}, {10.1, 0.1}, {10, 9.8},
+		{0.2, 10.1}, {0, 0}}, nil)
+	snapped = Snap(ring, 1, nil)
+	expect(t, snapped.Closed())
+	expect(t, snapped.NumPoints() == 5)
+	expect(t, snapped.PointAt(0) == snapped.PointAt(4))
+	expect(t, ringArea(snapped) == 100)
+
+	snapped = Snap(newRing(AZ, nil), 0.01, nil)
+	expect(t, snapped.NumPoints() < len(AZ))
+	for i := 1; i < snapped.NumPoints(); i++ {
+		expect(t, snapped.PointAt(i) != snapped.PointAt(i-1))
+	}
+
+	for _, gridSize := range []float64{0, -1} {
+		same := Snap(line, gridSize, nil)
+		expect(t, same.NumPoints() == line.NumPoints())
+		for i := 0; i < line.NumPoints(); i++ {
+			expect(t, same.PointAt(i) == line.PointAt(i))
+		}
+		expect(t, &same.RawPoints()[0] != &line.RawPoints()[0])
+	}
+}
+
+func TestEnsureClockwise(t *testing.T) {
+	for _, opts := range []*IndexOptions{NoIndexing, DefaultIndexOptions} {
+		octa := newRing(octagon, opts)
+		expect(t, !octa.Clockwise())
+		expect(t, EnsureCounterClockwise(octa) == octa)
+		cw := EnsureClockwise(octa)
+		expect(t, cw != octa)
+		expect(t, cw.Clockwise() && cw.Closed())
+		expect(t, len(cw.Index()) == len(octa.Index()))
+		n := octa.NumPoints()
+		for i := 0; i < n; i++ {
+			expect(t, cw.PointAt(i) == octa.PointAt(n-1-i))
+		}
+		expect(t, EnsureClockwise(cw) == cw)
+		ccw := EnsureCounterClockwise(cw)
+		expect(t, !ccw.Clockwise())
+		for i := 0; i < n; i++ {
+			expect(t, ccw.PointAt(i) == octa.PointAt(i))
+		}
+		az := newRing(AZ, opts)
+		expect(t, EnsureClockwise(az).Clockwise())
+		expect(t, !EnsureCounterClockwise(az).Clockwise())
+		expect(t, len(EnsureClockwise(az).Index()) == len(az.Index()))
+	}
+}
+
+func TestSeriesIntersections(t *testing.T) {
+	// a zigzag of horizontal lines crossed by a zigzag of vertical lines
+	var hpoints, vpoints []Point
+	const N = 20
+	for i := 0; i < N; i++ {
+		y := float64(i)*2 + 1
+		if i%2 == 0 {
+			hpoints = append(hpoints, P(0, y), P(N*2, y))
+		} else {
+			hpoints = append(hpoints, P(N*2, y), P(0, y))
+		}
+		x := float64(i)*2 + 1
+		if i%2 == 0 {
+			vpoints = append(vpoints, P(x, 0), P(x, N*2))
+		} else {
+			vpoints = append(vpoints, P(x, N*2), P(x, 0))
+		}
+	}
+	for _, opts := range []*IndexOptions{NoIndexing, DefaultIndexOptions} {
+		a := NewLine(hpoints, opts)
+		b := NewLine(vpoints, opts)
+		results := SeriesIntersections(a, b)
+		expect(t, len(results) == N*N)
+		for _, result := range results {
+			expect(t, a.SegmentAt(result.AIdx).ContainsPoint(result.Point))
+			expect(t, b.SegmentAt(result.BIdx).ContainsPoint(result.Point))
+			expect(t, result.AIdx%2 == 0 && result.BIdx%2 == 0)
+		}
+		for i := 1; i < len(results); i++ {
+			expect(t, results[i-1].AIdx <= results[i].AIdx)
+		}
+		// compare with the pairwise loop
+		var count int
+		for i := 0; i < a.NumSegments(); i++ {
+			for j := 0; j < b.NumSegments(); j++ {
+				count += len(appendIntersectionPoints(nil, a.SegmentAt(i),
+					b.SegmentAt(j)))
+			}
+		}
+		expect(t, count == N*N)
+	}
+
+	// crossing through a shared vertex is only reported once
+	a := NewLine([]Point{{0, 0}, {5, 5}, {10, 0}}, nil)
+	b := NewLine([]Point{{5, 0}, {5, 10}}, nil)
+	results := SeriesIntersections(a, b)
+	expect(t, len(results) == 1)
+	expect(t, results[0] == SeriesIntersection{P(5, 5), 0, 0})
+
+	// collinear overlap
+	a = NewLine([]Point{{0, 0}, {10, 0}}, nil)
+	b = NewLine([]Point{{5, 0}, {15, 0}, {15, 5}}, nil)
+	results = SeriesIntersections(a, b)
+	expect(t, len(results) == 2)
+	expect(t, results[0].Point == P(10, 0) || results[0].Point == P(5, 0))
+	expect(t, results[1].Point == P(10, 0) || results[1].Point == P(5, 0))
+
+	expect(t, len(SeriesIntersections(a, NewLine(nil, nil))) == 0)
+	expect(t, len(SeriesIntersections(a, NewLine([]Point{{0, 1}, {10, 1}},
+		nil))) == 0)
+}
+
+func TestAppendIntersectionPoints(t *testing.T) {
+	expect(t, len(appendIntersectionPoints(nil, S(0, 0, 10, 10),
+		S(0, 10, 10, 0))) == 1)
+	expect(t, appendIntersectionPoints(nil, S(0, 0, 10, 10),
+		S(0, 10, 10, 0))[0] == P(5, 5))
+	expect(t, appendIntersectionPoints(nil, S(0, 0, 10, 0),
+		S(10, 0, 10, 10))[0] == P(10, 0))
+	expect(t, appendIntersectionPoints(nil, S(0, 0, 10, 0),
+		S(3, -1, 3, 1))[0] == P(3, 0))
+	expect(t, len(appendIntersectionPoints(nil, S(0, 0, 10, 0),
+		S(0, 1, 10, 1))) == 0)
+	pts := appendIntersectionPoints(nil, S(0, 0, 10, 0), S(2, 0, 4, 0))
+	expect(t, len(pts) == 2 && pts[0] == P(2, 0) && pts[1] == P(4, 0))
+	pts = appendIntersectionPoints(nil, S(0, 0, 10, 0), S(10, 0, 20, 0))
+	expect(t, len(pts) == 1 && pts[0] == P(10, 0))
+	pts = appendIntersectionPoints(nil, S(1, 1, 1, 1), S(0, 0, 2, 2))
+	expect(t, len(pts) == 1 && pts[0] == P(1, 1))
+}
+
+func TestCountIntersectingSegments(t *testing.T) {
+	seed := time.Now().UnixNano()
+	rng := rand.New(rand.NewSource(seed))
+	for _, opts := range []*IndexOptions{NoIndexing, DefaultIndexOptions} {
+		ring := newRing(AZ, opts)
+		line := NewLine(AZ, opts)
+		rect := ring.Rect()
+		rects := []Rect{rect, R(0, 0, 1, 1), R(-112, 33, -111, 34)}
+		for i := 0; i < 100; i++ {
+			x := rect.Min.X + rng.Float64()*(rect.Max.X-rect.Min.X)
+			y := rect.Min.Y + rng.Float64()*(rect.Max.Y-rect.Min.Y)
+			rects = append(rects, R(x, y, x+rng.Float64()*2, y+rng.Float64()*2))
+		}
+		for _, r := range rects {
+			for _, series := range []Series{ring, line} {
+				var count int
+				series.Search(r, func(_ Segment, _ int) bool {
+					count++
+					return true
+				})
+				expect(t, CountIntersectingSegments(series, r) == count)
+			}
+		}
+		expect(t, CountIntersectingSegments(ring, rect) == ring.NumSegments())
+	}
+	expect(t, CountIntersectingSegments(R(0, 0, 10, 10), R(5, 5, 15, 15)) == 2)
+}
+
+func TestDistanceToSeriesMetric(t *testing.T) {
+	p := P(-111.1, 33.3)
+	dualPolyTest(t, AZ, nil, func(t *testing.T, poly *Poly) {
+		expSeg, expIdx, expDist := DistanceToSeries(
+			poly.Exterior,
+			func(rect Rect) float64 {
+				return rect.DistanceToPoint(p)
+			},
+			func(seg Segment) float64 {
+				return distPointToSegment(p, seg)
+			},
+		)
+		seg, idx, dist := DistanceToSeriesMetric(poly.Exterior,
+			EuclideanMetric(p))
+		expect(t, seg == expSeg && idx == expIdx)
+		expect(t, math.Abs(dist-expDist) < 1e-12)
+		expect(t, math.Abs(dist-1.866511) < 0.000001)
+
+		expSeg, expIdx, expDist = DistanceToSeriesGeo(poly.Exterior, p)
+		seg, idx, dist = DistanceToSeriesMetric(poly.Exterior,
+			HaversineMetric(p))
+		expect(t, seg == expSeg && idx == expIdx && dist == expDist)
+	})
+	_, _, dist := DistanceToSeriesMetric(&baseSeries{}, EuclideanMetric(p))
+	expect(t, math.IsNaN(dist))
+}
+
+func TestWalkIndex(t *testing.T) {
+	for _, series := range []Series{newRing(AZ, nil), NewLine(AZ, nil),
+		NewMutableSeries(AZ, nil)} {
+		var nodes, segments, maxDepth int
+		WalkIndex(series, func(bounds Rect, depth, segmentCount int) bool {
+			if depth == 0 {
+				expect(t, nodes == 0)
+				expect(t, bounds.ContainsRect(series.Rect()))
+			}
+			nodes++
+			segments += segmentCount
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+			return true
+		})
+		expect(t, nodes > 1)
+		expect(t, segments == series.NumSegments())
+		expect(t, maxDepth > 0 && maxDepth <= qMaxDepth)
+		// stop early
+		nodes = 0
+		WalkIndex(series, func(bounds Rect, depth, segmentCount int) bool {
+			nodes++
+			return nodes < 3
+		})
+		expect(t, nodes == 3)
+	}
+	var called bool
+	WalkIndex(newRing(octagon, nil), func(Rect, int, int) bool {
+		called = true
+		return true
+	})
+	expect(t, !called)
+}
+
+func TestHausdorffDistance(t *testing.T) {
+	for _, opts := range []*IndexOptions{NoIndexing, DefaultIndexOptions} {
+		az := NewLine(AZ, opts)
+		expect(t, HausdorffDistance(az, az) == 0)
+		moved := az.Move(3, 4)
+		expect(t, math.Abs(HausdorffDistance(az, moved)-5) < 1e-9)
+		expect(t, math.Abs(HausdorffDistance(moved, az)-5) < 1e-9)
+		// a simplified copy is within epsilon of the original
+		simple := SimplifyShared(az, 0.05, nil)
+		dist := HausdorffDistance(az, simple)
+		expect(t, dist > 0 && dist <= 0.05)
+	}
+	// not symmetric in each direction, so the max is used
+	a := NewLine([]Point{{0, 0}, {10, 0}}, nil)
+	b := NewLine([]Point{{0, 0}, {5, 0}}, nil)
+	expect(t, HausdorffDistance(a, b) == 5)
+	expect(t, HausdorffDistance(b, a) == 5)
+	expect(t, math.IsNaN(HausdorffDistance(a, NewLine(nil, nil))))
+}
+
+func TestDiscreteFrechetDistance(t *testing.T) {
+	var track, offset, reversed []Point
+	for i := 0; i < 100; i++ {
+		x := float64(i)
+		y := math.Sin(x / 10)
+		track = append(track, P(x, y))
+		offset = append(offset, P(x, y+2))
+	}
+	for i := len(track) - 1; i >= 0; i-- {
+		reversed = append(reversed, track[i])
+	}
+	a := NewLine(track, nil)
+	expect(t, DiscreteFrechetDistance(a, a) == 0)
+	expect(t, DiscreteFrechetDistance(a, NewLine(offset, nil)) == 2)
+	expect(t, DiscreteFrechetDistance(NewLine(offset, nil), a) == 2)
+	// the same shape in the opposite direction is far apart, even though
+	// the Hausdorff distance is zero.
+	b := NewLine(reversed, nil)
+	expect(t, HausdorffDistance(a, b) == 0)
+	expect(t, DiscreteFrechetDistance(a, b) > 90)
+	// different number of points
+	expect(t, DiscreteFrechetDistance(L(P(0, 0), P(10, 0)),
+		L(P(0, 1), P(5, 1), P(10, 1))) == math.Hypot(5, 1))
+	expect(t, math.IsNaN(DiscreteFrechetDistance(a, NewLine(nil, nil))))
+}
+
+func TestRemoveDuplicatePoints(t *testing.T) {
+	// the octagon with every vertex doubled
+	var doubled []Point
+	for _, point := range octagon[:len(octagon)-1] {
+		doubled = append(doubled, point, point)
+	}
+	doubled = append(doubled, octagon[0], octagon[0])
+	ring := newRing(doubled, nil)
+	expect(t, ring.NumSegments() == 17)
+	clean := RemoveDuplicatePoints(ring, 0)
+	expect(t, clean.Closed())
+	expect(t, clean.NumSegments() == 8)
+	expect(t, clean.NumPoints() == len(octagon))
+	for i := 0; i < clean.NumPoints(); i++ {
+		expect(t, clean.PointAt(i) == octagon[i])
+	}
+	expect(t, clean.Convex() && !clean.Clockwise())
+
+	// near duplicates, including the ones at the closing point
+	ring = newRing([]Point{{0, 0}, {0.001, 0}, {10, 0}, {10, 10},
+		{10.001, 10}, {0, 10}, {0, 0.001}, {0, 0}}, nil)
+	clean = RemoveDuplicatePoints(ring, 0.01)
+	expect(t, clean.NumSegments() == 4)
+	expect(t, clean.PointAt(0) == P(0, 0) && clean.PointAt(4) == P(0, 0))
+	expect(t, RemoveDuplicatePoints(ring, 0).NumSegments() == 7)
+
+	line := NewLine([]Point{{0, 0}, {0, 0}, {1, 1}, {1, 1}, {1, 1}}, nil)
+	clean = RemoveDuplicatePoints(line, 0)
+	expect(t, !clean.Closed() && clean.NumPoints() == 2)
+	expect(t, RemoveDuplicatePoints(NewLine(nil, nil), 0).NumPoints() == 0)
+}
+
+func TestSeriesClone(t *testing.T) {
+	for _, opts := range []*IndexOptions{NoIndexing, DefaultIndexOptions} {
+		series := makeSeries(AZ, true, true, opts)
+		clone := series.Clone()
+		expect(t, clone.NumPoints() == series.NumPoints())
+		expect(t, clone.Rect() == series.Rect())
+		expect(t, clone.Closed() == series.Closed())
+		expect(t, clone.Clockwise() == series.Clockwise())
+		expect(t, string(clone.Index()) == string(series.Index()))
+		orig := series.PointAt(10)
+		clone.RawPoints()[10] = P(1000, 1000)
+		expect(t, series.PointAt(10) == orig)
+		expect(t, clone.PointAt(10) == P(1000, 1000))
+		if len(series.Index()) > 0 {
+			b := series.Index()[5]
+			clone.Index()[5]++
+			expect(t, series.Index()[5] == b)
+		}
+	}
+	var empty baseSeries
+	expect(t, empty.Clone().Empty())
+}
+
+func TestSegmentIndexAt(t *testing.T) {
+	testDualRingX(t, octagon, func(t *testing.T, ring Ring) {
+		// the right edge
+		idx, ok := SegmentIndexAt(ring, P(10.05, 5), 0.1)
+		expect(t, ok && idx == 2)
+		// the diagonal from {10,7} to {7,10}
+		idx, ok = SegmentIndexAt(ring, P(8.55, 8.55), 0.1)
+		expect(t, ok && idx == 3)
+		// too far
+		_, ok = SegmentIndexAt(ring, P(10.2, 5), 0.1)
+		expect(t, !ok)
+		_, ok = SegmentIndexAt(ring, P(5, 5), 0.1)
+		expect(t, !ok)
+		// exactly on the edge
+		idx, ok = SegmentIndexAt(ring, P(5, 10), 0)
+		expect(t, ok && idx == 4)
+		// near a vertex, where the lowest index wins
+		idx, ok = SegmentIndexAt(ring, P(10, 3), 0.1)
+		expect(t, ok && idx == 1)
+		idx, ok = SegmentIndexAt(ring, P(3, 0), 0.1)
+		expect(t, ok && idx == 0)
+		idx, ok = SegmentIndexAt(ring, P(5, 0), -1)
+		expect(t, !ok && idx == -1)
+	})
+}
+
+func TestSeriesRecompute(t *testing.T) {
+	check := func(series *baseSeries) {
+		t.Helper()
+		fresh := makeSeries(series.points, true, series.closed, NoIndexing)
+		expect(t, series.Convex() == fresh.Convex())
+		expect(t, series.Clockwise() == fresh.Clockwise())
+		expect(t, series.Rect() == fresh.Rect())
+	}
+	series := makeSeries(octagon, true, true, nil)
+	expect(t, series.Convex())
+	// push a vertex inwards, which makes the ring concave
+	series.RawPoints()[1] = P(5, 5)
+	series.Recompute()
+	check(&series)
+	expect(t, !series.Convex())
+	// stretch the rect
+	series.RawPoints()[2] = P(50, -10)
+	series.Recompute()
+	check(&series)
+	expect(t, series.Rect().Max.X == 50 && series.Rect().Min.Y == -10)
+	// reverse the winding
+	points := series.RawPoints()
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+	series.Recompute()
+	check(&series)
+	// lines
+	line := makeSeries([]Point{{0, 0}, {1, 1}, {2, 0}}, true, false, nil)
+	line.RawPoints()[1] = P(1, -5)
+	line.Recompute()
+	check(&line)
+	// the index is rebuilt for the new rect
+	ring := makeSeries(AZ, true, true, DefaultIndexOptions)
+	expect(t, len(ring.Index()) > 0)
+	ring.RawPoints()[10] = ring.Rect().Max.Move(5, 5)
+	ring.RawPoints()[20] = ring.Rect().Min.Move(-5, -5)
+	ring.Recompute()
+	check(&ring)
+	fresh := makeSeries(ring.points, true, true, DefaultIndexOptions)
+	expect(t, string(ring.Index()) == string(fresh.Index()))
+	rect := ring.Rect()
+	for _, query := range []Rect{
+		rect, R(rect.Min.X, rect.Min.Y, rect.Center().X, rect.Center().Y),
+		R(rect.Max.X-6, rect.Max.Y-6, rect.Max.X, rect.Max.Y),
+		R(rect.Min.X, rect.Min.Y, rect.Min.X+6, rect.Min.Y+6),
+	} {
+		var a, b []int
+		ring.Search(query, func(seg Segment, idx int) bool {
+			a = append(a, idx)
+			return true
+		})
+		fresh.Search(query, func(seg Segment, idx int) bool {
+			b = append(b, idx)
+			return true
+		})
+		sort.Ints(a)
+		sort.Ints(b)
+		expect(t, len(a) > 0 && reflect.DeepEqual(a, b))
+	}
+}
+
+func TestCoordinatesClosed(t *testing.T) {
+	explicit := []Point{P(0, 0), P(10, 0), P(10, 10), P(0, 10), P(0, 0)}
+	implicit := []Point{P(0, 0), P(10, 0), P(10, 10), P(0, 10)}
+	for _, closed := range []bool{true, false} {
+		series := makeSeries(explicit, true, closed, DefaultIndexOptions)
+		expect(t, CoordinatesClosed(&series))
+		expect(t, series.Closed() == closed)
+		series = makeSeries(implicit, true, closed, DefaultIndexOptions)
+		expect(t, !CoordinatesClosed(&series))
+		expect(t, series.Closed() == closed)
+	}
+	expect(t, CoordinatesClosed(NewPoly(explicit, nil, nil).Exterior))
+	expect(t, !CoordinatesClosed(NewPoly(implicit, nil, nil).Exterior))
+	var empty baseSeries
+	expect(t, !CoordinatesClosed(&empty))
+	single := makeSeries([]Point{P(1, 1)}, true, false, nil)
+	expect(t, !CoordinatesClosed(&single))
+}
+
+func sortedInputPoints(n int) []Point {
+	rng := rand.New(rand.NewSource(1))
+	points := make([]Point, n)
+	for i := range points {
+		points[i] = P(rng.Float64()*1000, rng.Float64()*1000)
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].X < points[j].X
+	})
+	return points
+}
+
+func TestSeriesBulkLoad(t *testing.T) {
+	points := sortedInputPoints(2000)
+	plain := makeSeries(points, true, false, DefaultIndexOptions)
+	bulk := makeSeries(points, true, false,
+		&IndexOptions{Kind: QuadTree, MinPoints: 64, BulkLoad: true})
+	expect(t, len(bulk.Index()) > 0)
+	expect(t, bulk.Index()[0] == byte(QuadTree))
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 200; i++ {
+		x, y := rng.Float64()*1000, rng.Float64()*1000
+		rect := R(x, y, x+rng.Float64()*50, y+rng.Float64()*50)
+		var a, b []int
+		plain.Search(rect, func(seg Segment, idx int) bool {
+			a = append(a, idx)
+			return true
+		})
+		bulk.Search(rect, func(seg Segment, idx int) bool {
+			b = append(b, idx)
+			return true
+		})
+		sort.Ints(a)
+		sort.Ints(b)
+		expect(t, reflect.DeepEqual(a, b))
+	}
+	// too few points to index
+	small := makeSeries(points[:10], true, false,
+		&IndexOptions{Kind: QuadTree, MinPoints: 64, BulkLoad: true})
+	expect(t, small.Index() == nil)
+	// all points are the same
+	same := makeSeries([]Point{P(1, 1), P(1, 1), P(1, 1)}, true, false,
+		&IndexOptions{Kind: QuadTree, MinPoints: 1, BulkLoad: true})
+	var count int
+	same.Search(R(0, 0, 2, 2), func(seg Segment, idx int) bool {
+		count++
+		return true
+	})
+	expect(t, count == 2)
+}
+
+func TestSeriesKeepsIndexOptions(t *testing.T) {
+	opts := &IndexOptions{
+		Kind: QuadTree, MinPoints: 32, MaxNodeItems: 4, MaxDepth: 5,
+		BulkLoad: true,
+	}
+	// the index that a new series with the points and opts would have
+	fresh := func(series Series) string {
+		nseries := makeSeries(seriesCopyPoints(series), true, series.Closed(),
+			opts)
+		return string(nseries.Index())
+	}
+	series := makeSeries(AZ, true, true, opts)
+	expect(t, *seriesIndexOptions(&series) == *opts)
+	expect(t, string(series.Index()) != string(newRing(AZ, nil).Index()))
+	derived := []Series{
+		seriesReverse(&series),
+		Slice(&series, 10, 80),
+		SimplifyShared(&series, 0.001, nil),
+		RemoveDuplicatePoints(&series, 0.001),
+		Snap(&series, 0.0001, seriesIndexOptions(&series)),
+		series.Transform(Translate(0.5, 0.25).Multiply(Rotation(0.3))),
+	}
+	first, second := SplitAt(NewLine(AZ, opts), 40)
+	derived = append(derived, first, second)
+	for _, nseries := range derived {
+		expect(t, len(nseries.Index()) > 0)
+		expect(t, string(nseries.Index()) == fresh(nseries))
+	}
+	// an unindexed series stays unindexed
+	plain := makeSeries(AZ, true, true, NoIndexing)
+	expect(t, len(plain.Transform(Translate(1, 1)).Index()) == 0)
+	// rebuilt in place
+	moved := series
+	moved.points = seriesCopyPoints(&series)
+	moved.points[3] = moved.points[3].Move(10, 10)
+	moved.Recompute()
+	expect(t, string(moved.Index()) == fresh(&moved))
+	// the index is rebuilt the same, as it is for a Move
+	clone := series
+	clone.clearIndex()
+	clone.buildIndex()
+	expect(t, string(clone.Index()) == string(series.Index()))
+	// the options are changed after the series is created
+	line := NewLine(AZ, opts)
+	opts.MaxNodeItems = 16
+	expect(t, seriesIndexOptions(line).MaxNodeItems == 4)
+}
+
+func BenchmarkSearchSortedInput(b *testing.B) {
+	points := sortedInputPoints(100000)
+	rng := rand.New(rand.NewSource(3))
+	rects := make([]Rect, 1000)
+	for i := range rects {
+		x, y := rng.Float64()*1000, rng.Float64()*1000
+		rects[i] = R(x, y, x+10, y+10)
+	}
+	for _, bulkLoad := range []bool{false, true} {
+		name := "Default"
+		if bulkLoad {
+			name = "BulkLoad"
+		}
+		b.Run(name, func(b *testing.B) {
+			series := makeSeries(points, true, false, &IndexOptions{
+				Kind: QuadTree, MinPoints: 64, BulkLoad: bulkLoad,
+			})
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				series.Search(rects[i%len(rects)],
+					func(seg Segment, idx int) bool { return true })
+			}
+		})
+	}
+}
+
+func TestPointsInRect(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, series := range []Series{
+		newRing(AZ, DefaultIndexOptions),
+		newRing(AZ, NoIndexing),
+		newRing(AZ[:len(AZ)-1], DefaultIndexOptions),
+		NewLine(AZ, DefaultIndexOptions),
+		NewLine(sortedInputPoints(1000), DefaultIndexOptions),
+		newRing(octagon, nil),
+		NewLine([]Point{{1, 1}}, nil),
+		newRing([]Point{{1, 1}, {2, 2}}, nil),
+		R(0, 0, 10, 10),
+	} {
+		bounds := series.Rect()
+		for i := 0; i < 100; i++ {
+			x := bounds.Min.X + rng.Float64()*(bounds.Max.X-bounds.Min.X)
+			y := bounds.Min.Y + rng.Float64()*(bounds.Max.Y-bounds.Min.Y)
+			size := (bounds.Max.X - bounds.Min.X) / 2 * rng.Float64()
+			rect := R(x-size, y-size, x+size, y+size)
+			if i == 0 {
+				rect = bounds
+			}
+			seen := make(map[int]bool)
+			PointsInRect(series, rect, func(point Point, index int) bool {
+				expect(t, rect.ContainsPoint(point))
+				expect(t, series.PointAt(index) == point)
+				expect(t, !seen[index])
+				seen[index] = true
+				return true
+			})
+			for j := 0; j < series.NumPoints(); j++ {
+				expect(t, seen[j] == rect.ContainsPoint(series.PointAt(j)))
+			}
+		}
+	}
+	// stop early
+	var count int
+	PointsInRect(newRing(AZ, nil), newRing(AZ, nil).Rect(),
+		func(Point, int) bool {
+			count++
+			return count < 5
+		})
+	expect(t, count == 5)
+}
+
+func TestForEachSegmentReverse(t *testing.T) {
+	for _, series := range []Series{
+		NewLine(u1, nil),
+		newRing(octagon, nil),
+		newRing(octagon[:len(octagon)-1], nil),
+		R(0, 0, 10, 5),
+	} {
+		n := series.NumSegments()
+		var idxs []int
+		ForEachSegmentReverse(series, func(seg Segment, idx int) bool {
+			expect(t, seg == series.SegmentAt(idx))
+			idxs = append(idxs, idx)
+			return true
+		})
+		expect(t, len(idxs) == n)
+		for i, idx := range idxs {
+			expect(t, idx == n-1-i)
+		}
+	}
+	// forward direction is kept
+	line := NewLine([]Point{{0, 0}, {1, 0}, {2, 0}}, nil)
+	var segs []Segment
+	ForEachSegmentReverse(line, func(seg Segment, idx int) bool {
+		segs = append(segs, seg)
+		return true
+	})
+	expect(t, reflect.DeepEqual(segs, []Segment{S(1, 0, 2, 0), S(0, 0, 1, 0)}))
+	// stop early
+	var count int
+	ForEachSegmentReverse(newRing(octagon, nil), func(seg Segment,
+		idx int) bool {
+		count++
+		return idx > 5
+	})
+	expect(t, count == 3)
+	ForEachSegmentReverse(NewLine(nil, nil), func(Segment, int) bool {
+		t.Fatal("unexpected segment")
+		return true
+	})
+}
+
+func TestSnapPoint(t *testing.T) {
+	for _, opts := range []*IndexOptions{DefaultIndexOptions, NoIndexing} {
+		ring := newRing([]Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}}, opts)
+		// near a vertex
+		snapped, kind, idx, ok := SnapPoint(ring, P(10.2, 9.9), 0.5)
+		expect(t, ok && kind == SnapVertex && idx == 2 && snapped == P(10, 10))
+		// near a vertex, and inside of the ring
+		snapped, kind, idx, ok = SnapPoint(ring, P(9.8, 0.1), 0.5)
+		expect(t, ok && kind == SnapVertex && idx == 1 && snapped == P(10, 0))
+		// the last segment ends at the first point
+		snapped, kind, idx, ok = SnapPoint(ring, P(-0.1, 0.2), 0.5)
+		expect(t, ok && kind == SnapVertex && idx == 0 && snapped == P(0, 0))
+		// near an edge midpoint
+		snapped, kind, idx, ok = SnapPoint(ring, P(5, 0.3), 0.5)
+		expect(t, ok && kind == SnapEdge && idx == 0 && snapped == P(5, 0))
+		snapped, kind, idx, ok = SnapPoint(ring, P(-0.4, 5), 0.5)
+		expect(t, ok && kind == SnapEdge && idx == 3 && snapped == P(0, 5))
+		// too far away
+		snapped, _, idx, ok = SnapPoint(ring, P(5, 5), 0.5)
+		expect(t, !ok && idx == -1 && snapped == P(5, 5))
+		_, _, _, ok = SnapPoint(ring, P(10, 10), -1)
+		expect(t, !ok)
+	}
+	line := NewLine([]Point{{0, 0}, {10, 0}, {20, 0}}, nil)
+	snapped, kind, idx, ok := SnapPoint(line, P(20.1, 0), 0.5)
+	expect(t, ok && kind == SnapVertex && idx == 2 && snapped == P(20, 0))
+	snapped, kind, idx, ok = SnapPoint(line, P(15, -0.2), 0.5)
+	expect(t, ok && kind == SnapEdge && idx == 1 && snapped == P(15, 0))
+}
+
+func TestSeriesConcurrentSearch(t *testing.T) {
+	// Run with -race to detect any shared state.
+	ring := newRing(AZ, DefaultIndexOptions)
+	rect := ring.Rect()
+	rng := rand.New(rand.NewSource(1))
+	const n = 200
+	rects := make([]Rect, n)
+	points := make([]Point, n)
+	counts := make([]int, n)
+	dists := make([]float64, n)
+	for i := 0; i < n; i++ {
+		x := rect.Min.X + rng.Float64()*(rect.Max.X-rect.Min.X)
+		y := rect.Min.Y + rng.Float64()*(rect.Max.Y-rect.Min.Y)
+		rects[i] = R(x, y, x+0.5, y+0.5)
+		points[i] = P(x, y)
+		ring.Search(rects[i], func(seg Segment, idx int) bool {
+			counts[i]++
+			return true
+		})
+		_, _, dists[i] = DistanceToSeriesMetric(ring, EuclideanMetric(points[i]))
+	}
+	var wg sync.WaitGroup
+	errs := make(chan string, 8)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for k := 0; k < 500; k++ {
+				i := (g*131 + k) % n
+				var count int
+				ring.Search(rects[i], func(seg Segment, idx int) bool {
+					count++
+					return true
+				})
+				_, _, dist := DistanceToSeriesMetric(ring,
+					EuclideanMetric(points[i]))
+				if count != counts[i] || dist != dists[i] {
+					errs <- fmt.Sprintf("goroutine %d: mismatch at %d", g, i)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestSlice(t *testing.T) {
+	for _, series := range []Series{
+		NewLine(sortedInputPoints(1000), DefaultIndexOptions),
+		newRing(AZ, DefaultIndexOptions),
+		newRing(octagon, NoIndexing),
+		R(0, 0, 10, 10),
+	} {
+		n := series.NumPoints()
+		for _, span := range [][2]int{{0, n}, {1, n - 1}, {2, 5}, {n / 2, n}} {
+			start, end := span[0], span[1]
+			slice := Slice(series, start, end)
+			expect(t, !slice.Closed())
+			expect(t, slice.NumPoints() == end-start)
+			expect(t, slice.NumSegments() == end-start-1)
+			for i := 0; i < slice.NumSegments(); i++ {
+				expect(t, slice.SegmentAt(i) == series.SegmentAt(start+i))
+			}
+			if end-start >= 64 && len(series.Index()) > 0 {
+				expect(t, len(slice.Index()) > 0)
+			}
+			var count int
+			slice.Search(slice.Rect(), func(seg Segment, idx int) bool {
+				expect(t, seg == series.SegmentAt(start+idx))
+				count++
+				return true
+			})
+			expect(t, count == slice.NumSegments())
+		}
+	}
+	// the points are shared
+	line := NewLine(sortedInputPoints(100), nil)
+	slice := Slice(line, 10, 20)
+	expect(t, &slice.RawPoints()[0] == &line.RawPoints()[10])
+	expect(t, cap(slice.RawPoints()) == 10)
+	expect(t, Slice(line, 5, 5).Empty())
+	// bounds violations panic
+	for _, span := range [][2]int{{-1, 5}, {5, 101}, {6, 5}} {
+		func() {
+			defer func() { expect(t, recover() != nil) }()
+			Slice(line, span[0], span[1])
+		}()
+	}
+}
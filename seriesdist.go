@@ -0,0 +1,164 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"math"
+)
+
+// SeriesDistance returns the nearest pair of segments of the two series, and
+// the distance between them. The distance is zero when the series intersect,
+// and the returned segments are a pair that intersect. Both indexes are
+// traversed together, where pairs of quads are visited from nearest to
+// farthest, so that pairs of quads that are farther apart than the nearest
+// pair of segments are never opened. Returns NaN if either series has no
+// segments.
+func SeriesDistance(a, b Series) (aSeg, bSeg Segment, dist float64) {
+	if a.NumSegments() == 0 || b.NumSegments() == 0 {
+		return Segment{}, Segment{}, math.NaN()
+	}
+	ta, tb := newDistTree(a), newDistTree(b)
+	q := &distQueue{newDistPair(ta.root(), tb.root())}
+	var children []distNode
+	for q.Len() > 0 {
+		pair := heap.Pop(q).(distPair)
+		if pair.a.leaf && pair.b.leaf {
+			return pair.a.seg, pair.b.seg, pair.dist
+		}
+		// open the larger node
+		if !pair.a.leaf &&
+			(pair.b.leaf || pair.a.rect.Area() >= pair.b.rect.Area()) {
+			children = ta.children(children[:0], pair.a)
+			for _, child := range children {
+				heap.Push(q, newDistPair(child, pair.b))
+			}
+		} else {
+			children = tb.children(children[:0], pair.b)
+			for _, child := range children {
+				heap.Push(q, newDistPair(pair.a, child))
+			}
+		}
+	}
+	// unreachable, as both series have segments
+	return Segment{}, Segment{}, math.NaN()
+}
+
+// distNode is a quad of the index, or a single segment.
+type distNode struct {
+	leaf bool
+	rect Rect
+	addr int // address of the quad, or -1 for all segments of the series
+	seg  Segment
+}
+
+// distTree is a series with its compressed index, if any.
+type distTree struct {
+	series Series
+	base   *baseSeries
+	data   []byte
+}
+
+func newDistTree(series Series) distTree {
+	tree := distTree{series: series}
+	if base := seriesBase(series); base != nil && len(base.index) > 0 {
+		data := base.index
+		n := binary.LittleEndian.Uint32(data[1:])
+		tree.base, tree.data = base, data[:n:n]
+	}
+	return tree
+}
+
+// root returns the root quad, or a node with every segment when the series
+// is not indexed.
+func (tree distTree) root() distNode {
+	if tree.data == nil {
+		return distNode{rect: tree.series.Rect(), addr: -1}
+	}
+	return distNode{rect: tree.base.rect, addr: 5}
+}
+
+// children appends the segments and quads of the node to dst.
+func (tree distTree) children(dst []distNode, node distNode) []distNode {
+	if node.addr == -1 {
+		n := tree.series.NumSegments()
+		for i := 0; i < n; i++ {
+			seg := tree.series.SegmentAt(i)
+			dst = append(dst, distNode{leaf: true, rect: seg.Rect(), seg: seg})
+		}
+		return dst
+	}
+	data, addr := tree.data, node.addr
+	var nitems uint64
+	nitems, addr = readUvarint(data, addr)
+	var last uint64
+	for i := uint64(0); i < nitems; i++ {
+		var item uint64
+		item, addr = readUvarint(data, addr)
+		item += last
+		seg := tree.base.SegmentAt(int(item))
+		dst = append(dst, distNode{leaf: true, rect: seg.Rect(), seg: seg})
+		last = item
+	}
+	if data[addr] == 1 {
+		addr++
+		for q := 0; q < 4; q++ {
+			var qsize uint64
+			qsize, addr = readUvarint(data, addr)
+			if qsize == 0 {
+				// empty quad
+				continue
+			}
+			dst = append(dst, distNode{
+				rect: quadBounds(node.rect, q),
+				addr: addr,
+			})
+			addr += int(qsize)
+		}
+	}
+	return dst
+}
+
+// distPair is a pair of nodes from each series. The distance is exact for a
+// pair of segments, and is the lower bound for any other pair.
+type distPair struct {
+	dist float64
+	a, b distNode
+}
+
+func newDistPair(a, b distNode) distPair {
+	pair := distPair{a: a, b: b}
+	if a.leaf && b.leaf {
+		pair.dist = a.seg.DistanceToSegment(b.seg)
+	} else {
+		pair.dist = a.rect.DistanceToRect(b.rect)
+	}
+	return pair
+}
+
+// distQueue is a priority queue of pairs, ordered by distance. For equal
+// distances, pairs of segments come first.
+type distQueue []distPair
+
+func (q distQueue) Len() int { return len(q) }
+
+func (q distQueue) Less(i, j int) bool {
+	if q[i].dist != q[j].dist {
+		return q[i].dist < q[j].dist
+	}
+	return q[i].a.leaf && q[i].b.leaf && !(q[j].a.leaf && q[j].b.leaf)
+}
+
+func (q distQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *distQueue) Push(x interface{}) { *q = append(*q, x.(distPair)) }
+
+func (q *distQueue) Pop() interface{} {
+	old := *q
+	pair := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return pair
+}
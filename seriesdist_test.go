@@ -0,0 +1,81 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func seriesDistanceBrute(a, b Series) float64 {
+	min := math.Inf(+1)
+	for i := 0; i < a.NumSegments(); i++ {
+		for j := 0; j < b.NumSegments(); j++ {
+			dist := a.SegmentAt(i).DistanceToSegment(b.SegmentAt(j))
+			min = math.Min(min, dist)
+		}
+	}
+	return min
+}
+
+func TestSeriesDistance(t *testing.T) {
+	// separated squares
+	a := newRing([]Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}, nil)
+	b := newRing([]Point{{13, 2}, {20, 2}, {20, 8}, {13, 8}, {13, 2}}, nil)
+	aSeg, bSeg, dist := SeriesDistance(a, b)
+	expect(t, dist == 3)
+	expect(t, aSeg == S(10, 0, 10, 10))
+	expect(t, aSeg.DistanceToSegment(bSeg) == 3)
+	// overlapping squares
+	c := newRing([]Point{{5, 5}, {15, 5}, {15, 15}, {5, 15}, {5, 5}}, nil)
+	aSeg, bSeg, dist = SeriesDistance(a, c)
+	expect(t, dist == 0)
+	expect(t, aSeg.IntersectsSegment(bSeg))
+	// separated indexed polygons
+	az := newRing(AZ, DefaultIndexOptions).(*baseSeries)
+	moved := az.Move(az.Rect().Max.X-az.Rect().Min.X+0.5, 0.25)
+	aSeg, bSeg, dist = SeriesDistance(az, moved)
+	expect(t, math.Abs(dist-seriesDistanceBrute(az, moved)) < 1e-12)
+	expect(t, aSeg.DistanceToSegment(bSeg) == dist)
+	expect(t, dist > 0)
+	// overlapping indexed polygons
+	moved = az.Move(0.5, 0.25)
+	aSeg, bSeg, dist = SeriesDistance(az, moved)
+	expect(t, dist == 0)
+	expect(t, aSeg.IntersectsSegment(bSeg))
+	// one inside of the other, without touching
+	inner := newRing([]Point{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}}, nil)
+	_, _, dist = SeriesDistance(a, inner)
+	expect(t, dist == 2)
+	// lines, with and without indexes
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		var pa, pb []Point
+		for j := 0; j < 100; j++ {
+			pa = append(pa, P(rng.Float64()*100, rng.Float64()*100))
+			pb = append(pb, P(rng.Float64()*100+120, rng.Float64()*100))
+		}
+		for _, opts := range []*IndexOptions{DefaultIndexOptions, NoIndexing} {
+			la, lb := NewLine(pa, opts), NewLine(pb, DefaultIndexOptions)
+			_, _, dist := SeriesDistance(la, lb)
+			expect(t, dist == seriesDistanceBrute(la, lb))
+			_, _, dist = SeriesDistance(lb, la)
+			expect(t, dist == seriesDistanceBrute(la, lb))
+		}
+	}
+	// empty
+	_, _, dist = SeriesDistance(a, NewLine(nil, nil))
+	expect(t, math.IsNaN(dist))
+}
+
+func BenchmarkSeriesDistance(b *testing.B) {
+	az := newRing(AZ, DefaultIndexOptions).(*baseSeries)
+	moved := az.Move(az.Rect().Max.X-az.Rect().Min.X+0.5, 0.25)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		SeriesDistance(az, moved)
+	}
+}
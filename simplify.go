@@ -0,0 +1,104 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+// simplifyPoints returns the points that remain after applying the
+// Ramer-Douglas-Peucker algorithm. The first and last points, and the points
+// where keep returns true, are never removed. The keep param may be nil.
+func simplifyPoints(
+	points []Point, epsilon float64, keep func(point Point) bool,
+) []Point {
+	if len(points) < 3 {
+		return append([]Point(nil), points...)
+	}
+	kept := simplifyMask(points, epsilon, keep)
+	var simplified []Point
+	for i, point := range points {
+		if kept[i] {
+			simplified = append(simplified, point)
+		}
+	}
+	return simplified
+}
+
+// simplifyMask is like simplifyPoints, but returns which points remain.
+func simplifyMask(
+	points []Point, epsilon float64, keep func(point Point) bool,
+) []bool {
+	kept := make([]bool, len(points))
+	if len(points) < 3 {
+		for i := range kept {
+			kept[i] = true
+		}
+		return kept
+	}
+	kept[0], kept[len(points)-1] = true, true
+	if keep != nil {
+		for i, point := range points {
+			if keep(point) {
+				kept[i] = true
+			}
+		}
+	}
+	// simplify each span between the kept points
+	var stack [][2]int
+	for i, start := 1, 0; i < len(points); i++ {
+		if kept[i] {
+			stack = append(stack, [2]int{start, i})
+			start = i
+		}
+	}
+	for len(stack) > 0 {
+		span := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		seg := Segment{points[span[0]], points[span[1]]}
+		var maxDist float64
+		maxIdx := -1
+		for i := span[0] + 1; i < span[1]; i++ {
+			dist := seg.Distance(points[i])
+			if dist > maxDist {
+				maxDist, maxIdx = dist, i
+			}
+		}
+		if maxIdx != -1 && maxDist > epsilon {
+			kept[maxIdx] = true
+			stack = append(stack, [2]int{span[0], maxIdx},
+				[2]int{maxIdx, span[1]})
+		}
+	}
+	return kept
+}
+
+// SimplifyMask returns which points of the series are kept by the
+// Ramer-Douglas-Peucker algorithm, without creating a new series. The mask
+// has one value for each point, and the kept points are the same as the
+// points of the series returned by SimplifyShared with no fixed points. This
+// is useful for simplifying data that is stored alongside the points, such
+// as elevations or timestamps.
+func SimplifyMask(series Series, epsilon float64) []bool {
+	return simplifyMask(seriesCopyPoints(series), epsilon, nil)
+}
+
+// SimplifyShared returns a new series that is simplified using the
+// Ramer-Douglas-Peucker algorithm, where points that are farther than
+// epsilon from the simplified series are retained. Points that exactly match
+// a point in fixed are never removed, which allows for pinning the vertices
+// of a boundary that is shared with another series, so that both series
+// still align after being simplified. The new series is indexed in the same
+// manner as the original series.
+func SimplifyShared(series Series, epsilon float64, fixed []Point) Series {
+	var keep func(point Point) bool
+	if len(fixed) > 0 {
+		set := make(map[Point]bool, len(fixed))
+		for _, point := range fixed {
+			set[point] = true
+		}
+		keep = func(point Point) bool { return set[point] }
+	}
+	points := simplifyPoints(seriesCopyPoints(series), epsilon, keep)
+	nseries := makeSeries(points, false, series.Closed(),
+		seriesIndexOptions(series))
+	return &nseries
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSimplifyPoints(t *testing.T) {
+	points := []Point{{0, 0}, {1, 0.1}, {2, -0.1}, {3, 5}, {4, 6}, {5, 7},
+		{6, 8.1}, {7, 9}, {8, 9}, {9, 9}}
+	// only the collinear points are removed
+	simplified := simplifyPoints(points, 0, nil)
+	expect(t, len(simplified) == 8)
+	expect(t, simplified[4] == P(5, 7) && simplified[7] == P(9, 9))
+	simplified = simplifyPoints(points, 0.5, nil)
+	expect(t, len(simplified) == 5)
+	expect(t, simplified[0] == P(0, 0) && simplified[4] == P(9, 9))
+	expect(t, len(simplifyPoints(points, 100, nil)) == 2)
+	simplified = simplifyPoints(points, 100, func(p Point) bool {
+		return p == P(4, 6)
+	})
+	expect(t, len(simplified) == 3 && simplified[1] == P(4, 6))
+	expect(t, len(simplifyPoints(points[:2], 100, nil)) == 2)
+	expect(t, len(simplifyPoints(nil, 100, nil)) == 0)
+}
+
+func TestSimplifyShared(t *testing.T) {
+	// two rings that share a wiggly edge along x=10
+	var shared []Point
+	for i := 0; i <= 100; i++ {
+		y := float64(i) / 10
+		shared = append(shared, P(10+math.Sin(y*5)*0.2, y))
+	}
+	left := []Point{{0, 0}}
+	left = append(left, shared...)
+	left = append(left, P(0, 10), P(0, 0))
+	right := []Point{{20, 10}}
+	for i := len(shared) - 1; i >= 0; i-- {
+		right = append(right, shared[i])
+	}
+	right = append(right, P(20, 0), P(20, 10))
+
+	// pin the points that are where the shared edge turns
+	fixed := []Point{shared[0], shared[len(shared)-1]}
+	for i := 1; i < len(shared)-1; i++ {
+		if i%20 == 0 {
+			fixed = append(fixed, shared[i])
+		}
+	}
+	for _, opts := range []*IndexOptions{NoIndexing, DefaultIndexOptions} {
+		a := SimplifyShared(newRing(left, opts), 1, fixed)
+		b := SimplifyShared(newRing(right, opts), 1, fixed)
+		expect(t, a.Closed() && b.Closed())
+		expect(t, a.NumPoints() < len(left) && b.NumPoints() < len(right))
+		// the shared edges still align exactly
+		var aedge, bedge []Point
+		for i := 0; i < a.NumPoints(); i++ {
+			if a.PointAt(i).X > 5 {
+				aedge = append(aedge, a.PointAt(i))
+			}
+		}
+		for i := b.NumPoints() - 1; i >= 0; i-- {
+			if b.PointAt(i).X < 15 {
+				bedge = append(bedge, b.PointAt(i))
+			}
+		}
+		expect(t, len(aedge) == len(fixed) && len(aedge) == len(bedge))
+		for i := range aedge {
+			expect(t, aedge[i] == bedge[i])
+		}
+	}
+	// without pinning, all of the wiggles are removed
+	a := SimplifyShared(newRing(left, nil), 1, nil)
+	expect(t, a.NumPoints() == 5)
+}
+
+func TestSimplifyMask(t *testing.T) {
+	for _, series := range []Series{
+		NewLine(AZ, DefaultIndexOptions),
+		newRing(AZ, DefaultIndexOptions),
+		newRing(octagon, nil),
+		NewLine(u1, nil),
+		NewLine([]Point{{0, 0}, {1, 1}}, nil),
+		NewLine(nil, nil),
+	} {
+		for _, epsilon := range []float64{0, 0.001, 0.01, 0.1, 1} {
+			mask := SimplifyMask(series, epsilon)
+			expect(t, len(mask) == series.NumPoints())
+			var kept []Point
+			for i, keep := range mask {
+				if keep {
+					kept = append(kept, series.PointAt(i))
+				}
+			}
+			simplified := SimplifyShared(series, epsilon, nil)
+			expect(t, reflect.DeepEqual(kept, simplified.RawPoints()) ||
+				len(kept)+simplified.NumPoints() == 0)
+		}
+	}
+	// the mask subsets a parallel slice
+	line := NewLine([]Point{{0, 0}, {1, 0.01}, {2, 0}, {3, 5}, {4, 0}}, nil)
+	times := []int{10, 11, 12, 13, 14}
+	var kept []int
+	for i, keep := range SimplifyMask(line, 0.1) {
+		if keep {
+			kept = append(kept, times[i])
+		}
+	}
+	expect(t, reflect.DeepEqual(kept, []int{10, 12, 13, 14}))
+}
@@ -0,0 +1,475 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// The triangulation is a port of earcut (https://github.com/mapbox/earcut),
+// which is distributed under the following license.
+//
+// ISC License
+//
+// Copyright (c) 2016, Mapbox
+//
+// Permission to use, copy, modify, and/or distribute this software for any
+// purpose with or without fee is hereby granted, provided that the above
+// copyright notice and this permission notice appear in all copies.
+//
+// THE SOFTWARE IS PROVIDED "AS IS" AND ISC DISCLAIMS ALL WARRANTIES WITH
+// REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY
+// AND FITNESS. IN NO EVENT SHALL ISC BE LIABLE FOR ANY SPECIAL, DIRECT,
+// INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM
+// LOSS OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE
+// OR OTHER TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR
+// PERFORMANCE OF THIS SOFTWARE.
+
+package geometry
+
+import (
+	"math"
+	"sort"
+)
+
+// Triangulate divides the polygon into triangles using the ear clipping
+// algorithm. Each hole is first joined to the exterior with a bridge from
+// the leftmost vertex of the hole to a visible vertex of the exterior,
+// creating a single ring that is then clipped. The bridge is often described
+// from the rightmost vertex of the hole, with a ray cast to the right. That
+// is the mirror image of the same method, and either side always finds a
+// visible vertex, so the leftmost vertex is used to follow earcut, which the
+// triangulation is ported from. Rings that cannot be clipped,
+// such as those that touch themselves, are split along a diagonal and each
+// part is clipped on its own. All triangles are counter-clockwise, and the
+// sum of their areas is the area of the polygon.
+// Returns nil if the polygon is empty.
+func Triangulate(poly *Poly) [][3]Point {
+	if poly.Empty() {
+		return nil
+	}
+	var id int
+	outer := newEarRing(triangulateRingPoints(poly.Exterior, false), &id)
+	if outer == nil || outer.next == outer.prev {
+		return nil
+	}
+	if len(poly.Holes) > 0 {
+		outer = outer.eliminateHoles(poly.Holes, &id)
+	}
+	var triangles [][3]Point
+	outer.clip(&triangles, 0)
+	return triangles
+}
+
+// triangulateRingPoints returns the points of the ring without the closing
+// point, in clockwise or counter-clockwise order.
+func triangulateRingPoints(ring Ring, clockwise bool) []Point {
+	points := seriesCopyPoints(ring)
+	if len(points) > 1 && points[0] == points[len(points)-1] {
+		points = points[:len(points)-1]
+	}
+	if (signedArea(points) < 0) != clockwise {
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+	}
+	return points
+}
+
+// earNode is a vertex in a circular linked ring. The id identifies the
+// original vertex, and is shared by the copies that are made when a hole is
+// bridged or a ring is split.
+type earNode struct {
+	point      Point
+	id         int
+	prev, next *earNode
+	steiner    bool
+}
+
+// newEarRing returns a linked ring for the points, or nil if there are no
+// points.
+func newEarRing(points []Point, id *int) *earNode {
+	var last *earNode
+	for _, point := range points {
+		node := &earNode{point: point, id: *id}
+		*id++
+		if last == nil {
+			node.prev, node.next = node, node
+		} else {
+			node.next, node.prev = last.next, last
+			last.next.prev = node
+			last.next = node
+		}
+		last = node
+	}
+	if last != nil && last.point == last.next.point {
+		last.remove()
+		last = last.next
+	}
+	return last
+}
+
+// remove unlinks the node from its ring.
+func (node *earNode) remove() {
+	node.next.prev = node.prev
+	node.prev.next = node.next
+}
+
+// triArea returns twice the signed area of the triangle, which is negative
+// when the triangle is counter-clockwise.
+func triArea(p, q, r *earNode) float64 {
+	return (q.point.Y-p.point.Y)*(r.point.X-q.point.X) -
+		(q.point.X-p.point.X)*(r.point.Y-q.point.Y)
+}
+
+// pointInTriangle returns true if the point is inside of, or on the edge of,
+// the counter-clockwise triangle.
+func pointInTriangle(point, a, b, c Point) bool {
+	cross := func(a, b Point) float64 {
+		return (b.X-a.X)*(point.Y-a.Y) - (b.Y-a.Y)*(point.X-a.X)
+	}
+	return cross(a, b) >= 0 && cross(b, c) >= 0 && cross(c, a) >= 0
+}
+
+// clip cuts the ears from the ring and appends them to triangles. When no
+// ear can be found the ring is first cleaned up, then any local
+// self-intersections are cured, and finally the ring is split in two.
+func (ear *earNode) clip(triangles *[][3]Point, pass int) {
+	if ear == nil {
+		return
+	}
+	stop := ear
+	for ear.prev != ear.next {
+		prev, next := ear.prev, ear.next
+		if ear.isEar() {
+			*triangles = append(*triangles,
+				[3]Point{prev.point, ear.point, next.point})
+			ear.remove()
+			// skipping the next vertex leads to fewer sliver triangles
+			ear, stop = next.next, next.next
+			continue
+		}
+		ear = next
+		if ear == stop {
+			switch pass {
+			case 0:
+				ear.filter(nil).clip(triangles, 1)
+			case 1:
+				ear.filter(nil).cureLocalIntersections(triangles).
+					clip(triangles, 2)
+			case 2:
+				ear.split(triangles)
+			}
+			return
+		}
+	}
+}
+
+// isEar returns true if the node is a convex vertex and no reflex vertex of
+// the ring is inside of the triangle made with its neighbors.
+func (ear *earNode) isEar() bool {
+	a, b, c := ear.prev, ear, ear.next
+	if triArea(a, b, c) >= 0 {
+		return false
+	}
+	for p := c.next; p != a; p = p.next {
+		if p.point != a.point &&
+			pointInTriangle(p.point, a.point, b.point, c.point) &&
+			triArea(p.prev, p, p.next) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// filter removes the duplicate and collinear points between start and end,
+// and returns the last node that remains.
+func (start *earNode) filter(end *earNode) *earNode {
+	if end == nil {
+		end = start
+	}
+	p := start
+	for {
+		again := false
+		if !p.steiner && (p.point == p.next.point ||
+			triArea(p.prev, p, p.next) == 0) {
+			p.remove()
+			p, end = p.prev, p.prev
+			if p == p.next {
+				break
+			}
+			again = true
+		} else {
+			p = p.next
+		}
+		if !again && p == end {
+			break
+		}
+	}
+	return end
+}
+
+// cureLocalIntersections removes the small loops that are made by two
+// crossing edges that are one vertex apart.
+func (start *earNode) cureLocalIntersections(
+	triangles *[][3]Point,
+) *earNode {
+	p := start
+	for {
+		a, b := p.prev, p.next.next
+		if a.point != b.point && segmentsCross(a, p, p.next, b) &&
+			a.locallyInside(b) && b.locallyInside(a) {
+			*triangles = append(*triangles,
+				[3]Point{a.point, p.point, b.point})
+			p.next.remove()
+			p.remove()
+			p, start = b, b
+		}
+		p = p.next
+		if p == start {
+			break
+		}
+	}
+	return p.filter(nil)
+}
+
+// split looks for a valid diagonal that divides the ring in two, and clips
+// each part on its own.
+func (start *earNode) split(triangles *[][3]Point) {
+	a := start
+	for {
+		for b := a.next.next; b != a.prev; b = b.next {
+			if a.id != b.id && a.isValidDiagonal(b) {
+				c := a.splitRing(b)
+				a = a.filter(a.next)
+				c = c.filter(c.next)
+				a.clip(triangles, 0)
+				c.clip(triangles, 0)
+				return
+			}
+		}
+		a = a.next
+		if a == start {
+			return
+		}
+	}
+}
+
+// splitRing links the node to b with two bridges, dividing the ring in two.
+// The node stays in one ring, and the copy of b that is returned is in the
+// other ring.
+func (a *earNode) splitRing(b *earNode) *earNode {
+	a2 := &earNode{point: a.point, id: a.id}
+	b2 := &earNode{point: b.point, id: b.id}
+	an, bp := a.next, b.prev
+	a.next, b.prev = b, a
+	a2.next, an.prev = an, a2
+	b2.next, a2.prev = a2, b2
+	bp.next, b2.prev = b2, bp
+	return b2
+}
+
+// eliminateHoles links each hole into the outer ring, from left to right,
+// and returns the outer ring.
+func (outer *earNode) eliminateHoles(holes []Ring, id *int) *earNode {
+	var queue []*earNode
+	for _, hole := range holes {
+		ring := newEarRing(triangulateRingPoints(hole, true), id)
+		if ring == nil {
+			continue
+		}
+		if ring == ring.next {
+			ring.steiner = true
+		}
+		queue = append(queue, ring.leftmost())
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		a, b := queue[i].point, queue[j].point
+		if a.X != b.X {
+			return a.X < b.X
+		}
+		return a.Y < b.Y
+	})
+	for _, hole := range queue {
+		bridge := hole.findBridge(outer)
+		if bridge == nil {
+			continue
+		}
+		reverse := bridge.splitRing(hole)
+		reverse.filter(reverse.next)
+		outer = bridge.filter(bridge.next)
+	}
+	return outer
+}
+
+// leftmost returns the leftmost node of the ring.
+func (start *earNode) leftmost() *earNode {
+	p, leftmost := start, start
+	for {
+		if p.point.X < leftmost.point.X ||
+			(p.point.X == leftmost.point.X && p.point.Y < leftmost.point.Y) {
+			leftmost = p
+		}
+		p = p.next
+		if p == start {
+			return leftmost
+		}
+	}
+}
+
+// findBridge returns the outer node that the leftmost node of a hole can
+// connect to without crossing any edges. A ray is cast from the hole towards
+// negative X and the nearest edge's endpoint is chosen, unless a vertex
+// inside of the triangle between them has a smaller angle to the ray.
+func (hole *earNode) findBridge(outer *earNode) *earNode {
+	hx, hy := hole.point.X, hole.point.Y
+	qx := math.Inf(-1)
+	var m *earNode
+	p := outer
+	if hole.point == p.point {
+		return p
+	}
+	for {
+		if hole.point == p.next.point {
+			return p.next
+		}
+		if hy <= p.point.Y && hy >= p.next.point.Y &&
+			p.next.point.Y != p.point.Y {
+			x := p.point.X + (hy-p.point.Y)*(p.next.point.X-p.point.X)/
+				(p.next.point.Y-p.point.Y)
+			if x <= hx && x > qx {
+				qx = x
+				m = p
+				if p.next.point.X < p.point.X {
+					m = p.next
+				}
+				if x == hx {
+					// the hole touches the outer edge
+					return m
+				}
+			}
+		}
+		p = p.next
+		if p == outer {
+			break
+		}
+	}
+	if m == nil {
+		return nil
+	}
+	stop := m
+	mp := m.point
+	a, c := Point{qx, hy}, Point{hx, hy}
+	if hy < mp.Y {
+		a, c = c, a
+	}
+	tanMin := math.Inf(+1)
+	p = m
+	for {
+		if hx >= p.point.X && p.point.X >= mp.X && hx != p.point.X &&
+			pointInTriangle(p.point, a, mp, c) {
+			tan := math.Abs(hy-p.point.Y) / (hx - p.point.X)
+			if p.locallyInside(hole) && (tan < tanMin || (tan == tanMin &&
+				(p.point.X > m.point.X || (p.point.X == m.point.X &&
+					m.sectorContainsSector(p))))) {
+				m, tanMin = p, tan
+			}
+		}
+		p = p.next
+		if p == stop {
+			return m
+		}
+	}
+}
+
+// sectorContainsSector returns true if the sector of the node contains the
+// sector of p, which is at the same point.
+func (m *earNode) sectorContainsSector(p *earNode) bool {
+	return triArea(m.prev, m, p.prev) < 0 && triArea(p.next, m, m.next) < 0
+}
+
+// isValidDiagonal returns true if the node can be connected to b without
+// leaving the ring or crossing any of its edges.
+func (a *earNode) isValidDiagonal(b *earNode) bool {
+	if a.next.id == b.id || a.prev.id == b.id || a.intersectsRing(b) {
+		return false
+	}
+	if a.locallyInside(b) && b.locallyInside(a) && a.middleInside(b) &&
+		(triArea(a.prev, a, b.prev) != 0 || triArea(a, b.prev, b) != 0) {
+		return true
+	}
+	// a zero length diagonal between two convex vertices
+	return a.point == b.point && triArea(a.prev, a, a.next) > 0 &&
+		triArea(b.prev, b, b.next) > 0
+}
+
+// intersectsRing returns true if the diagonal from the node to b crosses
+// any edge of the ring.
+func (a *earNode) intersectsRing(b *earNode) bool {
+	p := a
+	for {
+		if p.id != a.id && p.next.id != a.id && p.id != b.id &&
+			p.next.id != b.id && segmentsCross(p, p.next, a, b) {
+			return true
+		}
+		p = p.next
+		if p == a {
+			return false
+		}
+	}
+}
+
+// locallyInside returns true if the diagonal from the node to b starts
+// inside of the ring.
+func (a *earNode) locallyInside(b *earNode) bool {
+	if triArea(a.prev, a, a.next) < 0 {
+		return triArea(a, b, a.next) >= 0 && triArea(a, a.prev, b) >= 0
+	}
+	return triArea(a, b, a.prev) < 0 || triArea(a, a.next, b) < 0
+}
+
+// middleInside returns true if the middle of the diagonal from the node to
+// b is inside of the ring.
+func (a *earNode) middleInside(b *earNode) bool {
+	px, py := (a.point.X+b.point.X)/2, (a.point.Y+b.point.Y)/2
+	var inside bool
+	p := a
+	for {
+		p1, p2 := p.point, p.next.point
+		if (p1.Y > py) != (p2.Y > py) && p2.Y != p1.Y &&
+			px < (p2.X-p1.X)*(py-p1.Y)/(p2.Y-p1.Y)+p1.X {
+			inside = !inside
+		}
+		p = p.next
+		if p == a {
+			return inside
+		}
+	}
+}
+
+// segmentsCross returns true if the segments p1,q1 and p2,q2 intersect,
+// including when they only touch.
+func segmentsCross(p1, q1, p2, q2 *earNode) bool {
+	o1 := triSign(triArea(p1, q1, p2))
+	o2 := triSign(triArea(p1, q1, q2))
+	o3 := triSign(triArea(p2, q2, p1))
+	o4 := triSign(triArea(p2, q2, q1))
+	return (o1 != o2 && o3 != o4) ||
+		(o1 == 0 && onSegment(p1.point, p2.point, q1.point)) ||
+		(o2 == 0 && onSegment(p1.point, q2.point, q1.point)) ||
+		(o3 == 0 && onSegment(p2.point, p1.point, q2.point)) ||
+		(o4 == 0 && onSegment(p2.point, q1.point, q2.point))
+}
+
+// onSegment returns true if q, which is collinear with p and r, is inside of
+// the rectangle of the segment p,r.
+func onSegment(p, q, r Point) bool {
+	return q.X <= math.Max(p.X, r.X) && q.X >= math.Min(p.X, r.X) &&
+		q.Y <= math.Max(p.Y, r.Y) && q.Y >= math.Min(p.Y, r.Y)
+}
+
+func triSign(x float64) int {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 Joshua J Baker. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math"
+	"testing"
+)
+
+func triangleArea(tri [3]Point) float64 {
+	a, b, c := tri[0], tri[1], tri[2]
+	return ((b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)) / 2
+}
+
+func testTriangulate(t *testing.T, poly *Poly) [][3]Point {
+	t.Helper()
+	tris := Triangulate(poly)
+	var area float64
+	for _, tri := range tris {
+		// counter-clockwise
+		expect(t, triangleArea(tri) > 0)
+		area += triangleArea(tri)
+		// every triangle is inside of the polygon
+		center := P((tri[0].X+tri[1].X+tri[2].X)/3,
+			(tri[0].Y+tri[1].Y+tri[2].Y)/3)
+		expect(t, poly.ContainsPoint(center))
+	}
+	expect(t, math.Abs(area-poly.Area()) < poly.Area()*1e-9)
+	return tris
+}
+
+func TestTriangulate(t *testing.T) {
+	square := []Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	hole := []Point{{3, 3}, {7, 3}, {7, 7}, {3, 7}, {3, 3}}
+	dualPolyTest(t, square, nil, func(t *testing.T, poly *Poly) {
+		tris := testTriangulate(t, poly)
+		expect(t, len(tris) == 2)
+	})
+	// donut
+	dualPolyTest(t, square, [][]Point{hole}, func(t *testing.T, poly *Poly) {
+		tris := testTriangulate(t, poly)
+		expect(t, len(tris) == 8)
+	})
+	// clockwise exterior with a counter-clockwise hole
+	var rsquare, rhole []Point
+	for i := len(square) - 1; i >= 0; i-- {
+		rsquare = append(rsquare, square[i])
+		rhole = append(rhole, hole[i])
+	}
+	testTriangulate(t, NewPoly(rsquare, [][]Point{rhole}, nil))
+	// several holes, including two that share an X
+	holes := [][]Point{
+		{{1, 1}, {2, 1}, {2, 2}, {1, 2}, {1, 1}},
+		{{1, 4}, {2, 4}, {2, 5}, {1, 5}, {1, 4}},
+		{{4, 1}, {8, 1}, {8, 2}, {4, 2}, {4, 1}},
+		{{5, 5}, {9, 8}, {5, 8}, {5, 5}},
+	}
+	dualPolyTest(t, square, holes, func(t *testing.T, poly *Poly) {
+		testTriangulate(t, poly)
+	})
+	for _, points := range [][]Point{octagon, concave1, concave2, concave3,
+		concave4, AZ} {
+		dualPolyTest(t, points, nil, func(t *testing.T, poly *Poly) {
+			tris := testTriangulate(t, poly)
+			n := poly.Exterior.NumPoints() - 1
+			expect(t, len(tris) <= n-2)
+		})
+	}
+	// collinear points along the edges
+	testTriangulate(t, NewPoly([]Point{{0, 0}, {5, 0}, {10, 0}, {10, 5},
+		{10, 10}, {5, 10}, {0, 10}, {0, 0}}, nil, nil))
+	var poly *Poly
+	expect(t, Triangulate(poly) == nil)
+	expect(t, Triangulate(NewPoly([]Point{{0, 0}, {1, 1}}, nil, nil)) == nil)
+}